@@ -4,10 +4,17 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os/exec"
+	"strings"
+	"sync"
 	"time"
 
 	"meetingbar/config"
@@ -24,128 +31,575 @@ const (
 	UserInfoScope = "https://www.googleapis.com/auth/userinfo.email"
 )
 
+// revokeURL is Google's token revocation endpoint
+var revokeURL = "https://oauth2.googleapis.com/revoke"
+
+// Google's authorization and user info endpoints, which tests point at a fake server
 var (
-	oauth2Config *oauth2.Config
+	oauthEndpoint    = google.Endpoint
+	userInfoEndpoint = "https://www.googleapis.com/"
 )
 
-func init() {
-	oauth2Config = &oauth2.Config{
-		RedirectURL:  "http://localhost:8080/callback",
+// newOAuth2Config returns the OAuth2 configuration for the given client. Each
+// flow and token refresh gets its own, so accounts using different clients
+// never see each other's credentials.
+func newOAuth2Config(creds config.OAuth2Config) *oauth2.Config {
+	// RedirectURL is set per flow, once the loopback listener has a port
+	return &oauth2.Config{
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
 		Scopes: []string{
 			CalendarScope,
 			UserInfoScope,
 		},
-		Endpoint: google.Endpoint,
+		Endpoint: oauthEndpoint,
 	}
 }
 
-func SetOAuth2Config(clientID, clientSecret string) {
-	oauth2Config.ClientID = clientID
-	oauth2Config.ClientSecret = clientSecret
-}
+// oauthCallbackTimeout is how long the flow waits for the user to finish in the browser
+const oauthCallbackTimeout = 5 * time.Minute
 
+// StartOAuth2Flow adds an account with the default OAuth client
 func StartOAuth2Flow(ctx context.Context, cfg *config.Config) (*config.Account, error) {
-	// Update OAuth2 config with stored credentials
-	if cfg.OAuth2.ClientID == "" || cfg.OAuth2.ClientSecret == "" {
-		return nil, fmt.Errorf("OAuth2 credentials not configured. Please set them in settings first")
+	creds, err := cfg.ClientCredentials("")
+	if err != nil {
+		return nil, err
 	}
-	
-	oauth2Config.ClientID = cfg.OAuth2.ClientID
-	oauth2Config.ClientSecret = cfg.OAuth2.ClientSecret
+
+	flow, err := newLoopbackFlow(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open browser to authorization URL
+	if err := openBrowser(flow.authURL); err != nil {
+		log.Printf("Failed to open browser automatically: %v", err)
+		fmt.Printf("Please open the following URL in your browser:\n%s\n", flow.authURL)
+	}
+
+	return flow.wait(ctx)
+}
+
+// loopbackFlow is an installed-app authorization code flow with PKCE (S256).
+// It listens on an OS-assigned port on 127.0.0.1 and its redirect URI points
+// there, so nothing has to be registered with Google and the callback is not
+// reachable from the network.
+type loopbackFlow struct {
+	conf     oauth2.Config
+	server   *http.Server
+	state    string
+	verifier string
+	authURL  string
+
+	codeChan  chan string
+	errorChan chan error
+
+	// accept, when set, vets the signed-in account before its token is stored
+	accept func(*config.Account) error
+}
+
+// newLoopbackFlow starts the callback listener and builds the consent page URL
+// for the given client, adding opts to the URL's parameters
+func newLoopbackFlow(creds config.OAuth2Config, opts ...oauth2.AuthCodeOption) (*loopbackFlow, error) {
 	// Generate state parameter for CSRF protection
 	state, err := generateState()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate state: %w", err)
 	}
 
-	// Channel to receive the authorization code
-	codeChan := make(chan string, 1)
-	errorChan := make(chan error, 1)
-
-	// Start HTTP server to handle OAuth callback
-	mux := http.NewServeMux()
-	server := &http.Server{Addr: ":8080", Handler: mux}
-	
-	// Add a root handler for debugging
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/callback" {
-			// Handle callback
-			if r.URL.Query().Get("state") != state {
-				http.Error(w, "Invalid state parameter", http.StatusBadRequest)
-				errorChan <- fmt.Errorf("invalid state parameter")
-				return
-			}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start OAuth callback listener: %w", err)
+	}
 
-			code := r.URL.Query().Get("code")
-			if code == "" {
-				http.Error(w, "Authorization code not found", http.StatusBadRequest)
-				errorChan <- fmt.Errorf("authorization code not found")
-				return
-			}
+	// Each flow gets its own config, since the redirect URI depends on the listener port
+	flow := &loopbackFlow{
+		conf:      *newOAuth2Config(creds),
+		state:     state,
+		verifier:  oauth2.GenerateVerifier(),
+		codeChan:  make(chan string, 1),
+		errorChan: make(chan error, 1),
+	}
+	flow.conf.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr().String())
+	opts = append(opts, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(flow.verifier))
+	flow.authURL = flow.conf.AuthCodeURL(state, opts...)
 
-			// Redirect to success page in web settings
-			http.Redirect(w, r, "http://localhost:8765/oauth-success", http.StatusTemporaryRedirect)
-
-			codeChan <- code
-		} else {
-			// Handle other paths
-			fmt.Fprintf(w, `
-			<html>
-			<head><title>MeetingBar OAuth Server</title></head>
-			<body>
-				<h2>MeetingBar OAuth Server</h2>
-				<p>Server is running and waiting for OAuth callback...</p>
-				<p>If you're seeing this, the server is working correctly.</p>
-			</body>
-			</html>
-			`)
-		}
-	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", flow.handleCallback)
+	flow.server = &http.Server{Handler: mux}
 
 	go func() {
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			errorChan <- fmt.Errorf("HTTP server error: %w", err)
+		if err := flow.server.Serve(listener); err != http.ErrServerClosed {
+			flow.fail(fmt.Errorf("HTTP server error: %w", err))
 		}
 	}()
 
+	return flow, nil
+}
+
+func (f *loopbackFlow) handleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Ignore requests that are not part of this flow instead of aborting it
+	if query.Get("state") != f.state {
+		writeCallbackPage(w, http.StatusBadRequest, "Sign-in failed", "The request does not belong to this sign-in. Start signing in again from MeetingBar.")
+		log.Printf("Ignoring OAuth callback with invalid state parameter")
+		return
+	}
+
+	if authErr := query.Get("error"); authErr != "" {
+		writeCallbackPage(w, http.StatusBadRequest, "Sign-in failed", "Authorization was not granted: "+authErr)
+		f.fail(fmt.Errorf("authorization failed: %s", authErr))
+		return
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		writeCallbackPage(w, http.StatusBadRequest, "Sign-in failed", "Authorization code not found")
+		f.fail(fmt.Errorf("authorization code not found"))
+		return
+	}
+
+	// The page comes from this listener, since MeetingBar may run without the web settings
+	writeCallbackPage(w, http.StatusOK, "Signed in to Google", "You can close this tab. MeetingBar shows the account once it has finished adding it.")
+
+	select {
+	case f.codeChan <- code:
+	default:
+	}
+}
+
+// callbackPage is shown in the browser at the end of the loopback flow
+var callbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}} - MeetingBar</title>
+    <style>
+        body { font-family: system-ui; text-align: center; padding: 50px; background: #f0f9ff; }
+        .result { color: white; padding: 20px; border-radius: 8px; background: {{if .OK}}#10b981{{else}}#ef4444{{end}}; }
+    </style>
+</head>
+<body>
+    <div class="result">
+        <h2>{{.Title}}</h2>
+        <p>{{.Message}}</p>
+    </div>
+</body>
+</html>`))
+
+func writeCallbackPage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	callbackPage.Execute(w, struct {
+		OK             bool
+		Title, Message string
+	}{status == http.StatusOK, title, message})
+}
+
+func (f *loopbackFlow) fail(err error) {
+	select {
+	case f.errorChan <- err:
+	default:
+	}
+}
+
+// wait blocks until the browser returns to the callback, exchanges the code
+// and adds the account. The callback listener is closed when it returns.
+func (f *loopbackFlow) wait(ctx context.Context) (*config.Account, error) {
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		server.Shutdown(ctx)
+		f.server.Shutdown(ctx)
 	}()
 
-	// Generate authorization URL
-	authURL := oauth2Config.AuthCodeURL(state, oauth2.AccessTypeOffline)
-	
-	// Open browser to authorization URL
-	if err := openBrowser(authURL); err != nil {
-		log.Printf("Failed to open browser automatically: %v", err)
-		fmt.Printf("Please open the following URL in your browser:\n%s\n", authURL)
-	}
-
 	// Wait for authorization code or timeout
 	select {
-	case code := <-codeChan:
-		return exchangeCodeForAccount(ctx, code)
-	case err := <-errorChan:
+	case code := <-f.codeChan:
+		token, err := f.conf.Exchange(ctx, code, oauth2.VerifierOption(f.verifier))
+		if err != nil {
+			return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+		}
+		account, err := lookupAccount(ctx, &f.conf, token)
+		if err != nil {
+			return nil, err
+		}
+		if f.accept != nil {
+			if err := f.accept(account); err != nil {
+				return nil, err
+			}
+		}
+		if err := storeAccountToken(account, token); err != nil {
+			return nil, err
+		}
+		return account, nil
+	case err := <-f.errorChan:
 		return nil, err
-	case <-time.After(5 * time.Minute):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(oauthCallbackTimeout):
 		return nil, fmt.Errorf("authorization timeout")
 	}
 }
 
-func exchangeCodeForAccount(ctx context.Context, code string) (*config.Account, error) {
-	// Exchange authorization code for token
-	token, err := oauth2Config.Exchange(ctx, code)
+// DeviceFlow is a pending OAuth 2.0 device authorization. The user approves it
+// by entering UserCode at VerificationURL on any device with a browser.
+type DeviceFlow struct {
+	UserCode        string
+	VerificationURL string
+	ExpiresAt       time.Time
+
+	conf     oauth2.Config
+	response *oauth2.DeviceAuthResponse
+}
+
+// StartDeviceFlow requests a user code for adding an account on machines where
+// no local browser can reach the loopback callback, such as SSH sessions. The
+// default OAuth client must be of the "TVs and Limited Input devices" type.
+func StartDeviceFlow(ctx context.Context, cfg *config.Config) (*DeviceFlow, error) {
+	creds, err := cfg.ClientCredentials("")
+	if err != nil {
+		return nil, err
+	}
+	return startDeviceFlow(ctx, creds)
+}
+
+func startDeviceFlow(ctx context.Context, creds config.OAuth2Config) (*DeviceFlow, error) {
+	flow := &DeviceFlow{conf: *newOAuth2Config(creds)}
+	response, err := flow.conf.DeviceAuth(ctx)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return nil, fmt.Errorf("failed to request device code, check that the OAuth client type is \"TVs and Limited Input devices\": %w", err)
+		}
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+
+	flow.response = response
+	flow.UserCode = response.UserCode
+	flow.VerificationURL = response.VerificationURI
+	flow.ExpiresAt = response.Expiry
+
+	fmt.Printf("To add your Google account, open %s on any device and enter the code: %s\n", flow.VerificationURL, flow.UserCode)
+	return flow, nil
+}
+
+// Wait polls for the token until the user approves the request, denies it or
+// the code expires. The polling interval grows whenever the server answers
+// slow_down, as RFC 8628 requires.
+func (f *DeviceFlow) Wait(ctx context.Context) (*config.Account, error) {
+	token, err := f.conf.DeviceAccessToken(ctx, f.response)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			switch retrieveErr.ErrorCode {
+			case "access_denied":
+				return nil, fmt.Errorf("authorization was denied")
+			case "expired_token":
+				return nil, fmt.Errorf("device code expired, please start again")
+			}
+		}
+		if errors.Is(err, context.DeadlineExceeded) && !f.ExpiresAt.IsZero() && !time.Now().Before(f.ExpiresAt) {
+			return nil, fmt.Errorf("device code expired, please start again")
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	account, err := lookupAccount(ctx, &f.conf, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := storeAccountToken(account, token); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// OAuthSessionStatus is the state of a sign-in started by an OAuthSessionManager
+type OAuthSessionStatus string
+
+const (
+	OAuthWaiting   OAuthSessionStatus = "waiting"
+	OAuthSucceeded OAuthSessionStatus = "succeeded"
+	OAuthFailed    OAuthSessionStatus = "failed"
+	OAuthCancelled OAuthSessionStatus = "cancelled"
+)
+
+const (
+	OAuthModeBrowser = "browser"
+	OAuthModeDevice  = "device"
+)
+
+// finishedSessionRetention is how long the outcome of a sign-in stays available for polling
+const finishedSessionRetention = 10 * time.Minute
+
+// ErrOAuthCancelled is passed to the finish callback of a cancelled sign-in
+var ErrOAuthCancelled = errors.New("sign-in was cancelled")
+
+// OAuthSession is a snapshot of a pending or finished sign-in
+type OAuthSession struct {
+	ID              string
+	Mode            string
+	AuthURL         string
+	UserCode        string
+	VerificationURL string
+	ExpiresAt       time.Time
+	Status          OAuthSessionStatus
+	Error           string
+	Account         *config.Account
+	FinishedAt      time.Time
+}
+
+// Describe returns the status as shown to the user, e.g. "failed: authorization timeout"
+func (s OAuthSession) Describe() string {
+	if s.Status == OAuthFailed && s.Error != "" {
+		return fmt.Sprintf("%s: %s", s.Status, s.Error)
+	}
+	return string(s.Status)
+}
+
+// OAuthFinishFunc is called once a sign-in ends, with the new account on
+// success or the reason it failed. An error returned for a successful sign-in,
+// for example when the account cannot be saved, marks the session as failed.
+type OAuthFinishFunc func(account *config.Account, err error) error
+
+type oauthSessionEntry struct {
+	session OAuthSession
+	cancel  context.CancelFunc
+}
+
+// OAuthSessionManager owns the sign-ins started from the settings UIs. Each
+// session keeps its own state and PKCE verifier, so the URL handed to the
+// browser always belongs to the callback that is waiting for it.
+type OAuthSessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*oauthSessionEntry
+}
+
+func NewOAuthSessionManager() *OAuthSessionManager {
+	return &OAuthSessionManager{
+		sessions: make(map[string]*oauthSessionEntry),
+	}
+}
+
+// StartBrowser starts a loopback sign-in with the named OAuth client ("" for
+// the default one) and returns its session. The caller is responsible for
+// sending the user to AuthURL.
+func (m *OAuthSessionManager) StartBrowser(ctx context.Context, cfg *config.Config, client string, onFinish OAuthFinishFunc) (OAuthSession, error) {
+	id, err := generateState()
+	if err != nil {
+		return OAuthSession{}, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	creds, err := cfg.ClientCredentials(client)
+	if err != nil {
+		return OAuthSession{}, err
+	}
+
+	flow, err := newLoopbackFlow(creds)
+	if err != nil {
+		return OAuthSession{}, err
+	}
+
+	session := OAuthSession{
+		ID:        id,
+		Mode:      OAuthModeBrowser,
+		AuthURL:   flow.authURL,
+		ExpiresAt: time.Now().Add(oauthCallbackTimeout),
+	}
+	return m.start(ctx, session, withOAuthClient(flow.wait, client), onFinish), nil
+}
+
+// StartReauth signs in again to an existing account whose credentials stopped
+// working. Google preselects the account and asks for consent again, so a new
+// refresh token is issued; signing in to a different account fails the session.
+func (m *OAuthSessionManager) StartReauth(ctx context.Context, cfg *config.Config, account config.Account, onFinish OAuthFinishFunc) (OAuthSession, error) {
+	id, err := generateState()
+	if err != nil {
+		return OAuthSession{}, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	creds, err := cfg.ClientCredentials(account.OAuthClient)
+	if err != nil {
+		return OAuthSession{}, err
+	}
+
+	flow, err := newLoopbackFlow(creds,
+		oauth2.SetAuthURLParam("login_hint", account.Email),
+		oauth2.SetAuthURLParam("prompt", "consent"))
+	if err != nil {
+		return OAuthSession{}, err
+	}
+
+	// Checked before the token is stored, so signing in to another account
+	// cannot overwrite that account's token
+	flow.accept = func(signedIn *config.Account) error {
+		if signedIn.ID != account.ID {
+			return fmt.Errorf("signed in as %s instead of %s", signedIn.Email, account.Email)
+		}
+		return nil
+	}
+
+	wait := func(ctx context.Context) (*config.Account, error) {
+		signedIn, err := flow.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		signedIn.OAuthClient = account.OAuthClient
+		return signedIn, nil
+	}
+
+	session := OAuthSession{
+		ID:        id,
+		Mode:      OAuthModeBrowser,
+		AuthURL:   flow.authURL,
+		ExpiresAt: time.Now().Add(oauthCallbackTimeout),
+	}
+	return m.start(ctx, session, wait, onFinish), nil
+}
+
+// StartDevice starts a device code sign-in with the named OAuth client ("" for
+// the default one) and returns its session with the code the user has to
+// enter at VerificationURL
+func (m *OAuthSessionManager) StartDevice(ctx context.Context, cfg *config.Config, client string, onFinish OAuthFinishFunc) (OAuthSession, error) {
+	id, err := generateState()
+	if err != nil {
+		return OAuthSession{}, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	creds, err := cfg.ClientCredentials(client)
+	if err != nil {
+		return OAuthSession{}, err
+	}
+
+	flow, err := startDeviceFlow(ctx, creds)
+	if err != nil {
+		return OAuthSession{}, err
+	}
+
+	session := OAuthSession{
+		ID:              id,
+		Mode:            OAuthModeDevice,
+		UserCode:        flow.UserCode,
+		VerificationURL: flow.VerificationURL,
+		ExpiresAt:       flow.ExpiresAt,
+	}
+	return m.start(ctx, session, withOAuthClient(flow.Wait, client), onFinish), nil
+}
+
+// withOAuthClient records on the signed-in account which OAuth client it uses
+func withOAuthClient(wait func(context.Context) (*config.Account, error), client string) func(context.Context) (*config.Account, error) {
+	return func(ctx context.Context) (*config.Account, error) {
+		account, err := wait(ctx)
+		if account != nil {
+			account.OAuthClient = client
+		}
+		return account, err
+	}
+}
+
+func (m *OAuthSessionManager) start(ctx context.Context, session OAuthSession, wait func(context.Context) (*config.Account, error), onFinish OAuthFinishFunc) OAuthSession {
+	ctx, cancel := context.WithCancel(ctx)
+	session.Status = OAuthWaiting
+
+	m.mu.Lock()
+	m.pruneLocked(time.Now())
+	m.sessions[session.ID] = &oauthSessionEntry{session: session, cancel: cancel}
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		account, err := wait(ctx)
+		m.finish(session.ID, account, err, onFinish)
+	}()
+
+	return session
+}
+
+// finish runs the callback and records the outcome of a session
+func (m *OAuthSessionManager) finish(id string, account *config.Account, err error, onFinish OAuthFinishFunc) {
+	m.mu.Lock()
+	cancelled := m.sessions[id].session.Status == OAuthCancelled
+	m.mu.Unlock()
+
+	// A sign-in that completed just as it was cancelled is not added
+	if cancelled {
+		account, err = nil, ErrOAuthCancelled
+	}
+
+	if onFinish != nil {
+		if finishErr := onFinish(account, err); finishErr != nil && err == nil {
+			err = finishErr
+		}
+	}
+
+	if cancelled {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session := &m.sessions[id].session
+	session.FinishedAt = time.Now()
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+		log.Printf("OAuth sign-in failed: %v", err)
+		session.Status = OAuthFailed
+		session.Error = err.Error()
+		return
+	}
+	session.Status = OAuthSucceeded
+	session.Account = account
+}
+
+// Status returns the current snapshot of a session
+func (m *OAuthSessionManager) Status(id string) (OAuthSession, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.sessions[id]
+	if !ok {
+		return OAuthSession{}, false
+	}
+	return entry.session, true
+}
+
+// Cancel stops a pending session and closes its callback listener or polling
+func (m *OAuthSessionManager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.sessions[id]
+	if !ok {
+		return fmt.Errorf("sign-in session not found")
+	}
+	if entry.session.Status != OAuthWaiting {
+		return nil
+	}
+
+	entry.session.Status = OAuthCancelled
+	entry.session.FinishedAt = time.Now()
+	entry.cancel()
+	return nil
+}
+
+// pruneLocked forgets sessions that finished a while ago; callers must hold m.mu
+func (m *OAuthSessionManager) pruneLocked(now time.Time) {
+	for id, entry := range m.sessions {
+		if entry.session.Status != OAuthWaiting && now.Sub(entry.session.FinishedAt) > finishedSessionRetention {
+			delete(m.sessions, id)
+		}
 	}
+}
 
+// lookupAccount looks up the user a token was issued for
+func lookupAccount(ctx context.Context, conf *oauth2.Config, token *oauth2.Token) (*config.Account, error) {
 	// Create OAuth2 client
-	client := oauth2Config.Client(ctx, token)
+	client := conf.Client(ctx, token)
 	
 	// Get user info to determine email
-	userInfoService, err := oauth2api.NewService(ctx, option.WithHTTPClient(client))
+	userInfoService, err := oauth2api.NewService(ctx, option.WithHTTPClient(client), option.WithEndpoint(userInfoEndpoint))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user info service: %w", err)
 	}
@@ -157,45 +611,216 @@ func exchangeCodeForAccount(ctx context.Context, code string) (*config.Account,
 
 	// Create account
 	account := &config.Account{
-		ID:      userInfo.Id,
-		Email:   userInfo.Email,
-		AddedAt: time.Now(),
+		ID:            userInfo.Id,
+		Email:         userInfo.Email,
+		AddedAt:       time.Now(),
+		MissingScopes: missingScopes(conf.Scopes, token),
+	}
+	if len(account.MissingScopes) > 0 {
+		log.Printf("Warning: %s did not grant access to: %s", account.Email, strings.Join(account.MissingScopes, ", "))
 	}
 
-	// Store token securely
+	return account, nil
+}
+
+// storeAccountToken stores the token of a signed-in account securely
+func storeAccountToken(account *config.Account, token *oauth2.Token) error {
 	if err := config.StoreToken(account.ID, token); err != nil {
-		return nil, fmt.Errorf("failed to store token: %w", err)
+		return fmt.Errorf("failed to store token: %w", err)
 	}
+	return nil
+}
 
-	return account, nil
+// AuthErrorKind classifies why the stored credentials of an account cannot be used
+type AuthErrorKind string
+
+const (
+	// AuthRevoked means Google rejected the refresh token (invalid_grant), e.g. access was revoked
+	AuthRevoked AuthErrorKind = "revoked"
+	// AuthTokenMissing means the keyring has no token for the account
+	AuthTokenMissing AuthErrorKind = "token_missing"
+	// AuthClientMissing means the OAuth client ID or secret is missing or was rejected
+	AuthClientMissing AuthErrorKind = "client_missing"
+)
+
+// AuthError is returned for an account that has to be signed in again, or
+// whose OAuth client has to be configured, before its calendars can be read
+type AuthError struct {
+	AccountID string
+	Kind      AuthErrorKind
+	Err       error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("credentials for account %s are not usable (%s): %v", e.AccountID, e.Kind, e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Reason describes the error for the user
+func (e *AuthError) Reason() string {
+	switch e.Kind {
+	case AuthRevoked:
+		return "Access was revoked or has expired"
+	case AuthTokenMissing:
+		return "No saved sign-in was found in the keyring"
+	case AuthClientMissing:
+		return "The OAuth2 client ID or secret is missing or invalid"
+	default:
+		return e.Err.Error()
+	}
+}
+
+// AsAuthError returns the AuthError in err's chain, if any
+func AsAuthError(err error) (*AuthError, bool) {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return authErr, true
+	}
+	return nil, false
+}
+
+// classifyRefreshError wraps token refresh errors that signing in again can fix
+func classifyRefreshError(accountID string, err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		switch retrieveErr.ErrorCode {
+		case "invalid_grant":
+			return &AuthError{AccountID: accountID, Kind: AuthRevoked, Err: err}
+		case "invalid_client", "unauthorized_client":
+			return &AuthError{AccountID: accountID, Kind: AuthClientMissing, Err: err}
+		}
+	}
+	return err
+}
+
+// missingScopes returns the requested scopes that the user left unchecked on
+// the consent screen, according to the scope list of the token response
+func missingScopes(requested []string, token *oauth2.Token) []string {
+	grantedList, _ := token.Extra("scope").(string)
+	if grantedList == "" {
+		// Nothing to compare against; Google always includes the list
+		return nil
+	}
+
+	granted := make(map[string]bool)
+	for _, scope := range strings.Fields(grantedList) {
+		granted[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range requested {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// MissingScopesWarning explains what an account cannot do because of the
+// scopes it did not grant, or returns "" when everything was granted
+func MissingScopesWarning(account *config.Account) string {
+	for _, scope := range account.MissingScopes {
+		if scope == CalendarScope {
+			return "Calendar access was not granted, so no meetings can be shown for this account. Remove it and add it again, and tick the calendar permission on Google's consent screen."
+		}
+	}
+	if len(account.MissingScopes) > 0 {
+		return "Some permissions were not granted: " + strings.Join(account.MissingScopes, ", ")
+	}
+	return ""
 }
 
-func GetClientForAccount(ctx context.Context, accountID string) (*http.Client, error) {
+// RevokeAccount revokes the account's grant at Google and removes its token
+// from the keyring. The token is removed even if revocation fails, in which
+// case the error is returned so the user can revoke access manually.
+func RevokeAccount(ctx context.Context, accountID string) error {
 	token, err := config.GetToken(accountID)
+	if errors.Is(err, config.ErrTokenNotFound) {
+		return nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token for account %s: %w", accountID, err)
+		return fmt.Errorf("failed to get token for account %s: %w", accountID, err)
+	}
+
+	revokeErr := revokeToken(ctx, token)
+
+	if err := config.DeleteToken(accountID); err != nil {
+		return fmt.Errorf("failed to remove token from keyring: %w", err)
+	}
+	return revokeErr
+}
+
+// revokeToken revokes the grant behind the token. Revoking the refresh token
+// also invalidates its access tokens; a token Google no longer knows counts
+// as revoked.
+func revokeToken(ctx context.Context, token *oauth2.Token) error {
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return nil
 	}
 
-	// Ensure OAuth2 config is properly initialized with stored credentials
-	cfg, err := config.Load()
+	req, err := http.NewRequestWithContext(ctx, "POST", revokeURL, strings.NewReader(url.Values{"token": {value}}.Encode()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return fmt.Errorf("failed to create revocation request: %w", err)
 	}
-	
-	if cfg.OAuth2.ClientID == "" || cfg.OAuth2.ClientSecret == "" {
-		return nil, fmt.Errorf("OAuth2 credentials not configured")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.Error == "invalid_token" {
+		return nil
+	}
+	return fmt.Errorf("failed to revoke token: %s", resp.Status)
+}
+
+// GetClientForAccount returns an HTTP client authorized as the account, using
+// the OAuth client the account signed in with in cfg
+func GetClientForAccount(ctx context.Context, cfg *config.Config, accountID string) (*http.Client, error) {
+	token, err := config.GetToken(accountID)
+	if errors.Is(err, config.ErrTokenNotFound) {
+		return nil, &AuthError{AccountID: accountID, Kind: AuthTokenMissing, Err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token for account %s: %w", accountID, err)
+	}
+
+	// Refresh with the client the account signed in with
+	client := ""
+	for _, account := range cfg.Accounts {
+		if account.ID == accountID {
+			client = account.OAuthClient
+		}
+	}
+	creds, err := cfg.ClientCredentials(client)
+	if err != nil {
+		return nil, &AuthError{AccountID: accountID, Kind: AuthClientMissing, Err: err}
 	}
-	
-	oauth2Config.ClientID = cfg.OAuth2.ClientID
-	oauth2Config.ClientSecret = cfg.OAuth2.ClientSecret
 
 	// Create token source that automatically refreshes
-	tokenSource := oauth2Config.TokenSource(ctx, token)
+	tokenSource := newOAuth2Config(creds).TokenSource(ctx, token)
 	
 	// Check if token needs refresh and update stored token
 	refreshedToken, err := tokenSource.Token()
 	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
+		return nil, fmt.Errorf("failed to refresh token: %w", classifyRefreshError(accountID, err))
 	}
 	
 	if refreshedToken.AccessToken != token.AccessToken {
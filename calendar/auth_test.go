@@ -0,0 +1,326 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"meetingbar/config"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+// useTestSecretStore keeps tokens in an in-memory keyring for the test
+func useTestSecretStore(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	keyring.MockInit()
+	config.UseSecretStore(config.SecretStoreKeyring)
+}
+
+// fakeGoogle is an authorization, token and user info server
+type fakeGoogle struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	tokenRequests []url.Values
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	t.Helper()
+	fake := &fakeGoogle{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fake.mu.Lock()
+		fake.tokenRequests = append(fake.tokenRequests, r.PostForm)
+		fake.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-token",
+			"refresh_token": "refresh-token",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"scope":         CalendarScope + " " + UserInfoScope,
+		})
+	})
+	mux.HandleFunc("/oauth2/v2/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"id": "user-1", "email": "user@example.com"})
+	})
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+
+	previousEndpoint, previousUserInfo := oauthEndpoint, userInfoEndpoint
+	oauthEndpoint = oauth2.Endpoint{
+		AuthURL:   fake.URL + "/auth",
+		TokenURL:  fake.URL + "/token",
+		AuthStyle: oauth2.AuthStyleInParams,
+	}
+	userInfoEndpoint = fake.URL + "/"
+	t.Cleanup(func() {
+		oauthEndpoint, userInfoEndpoint = previousEndpoint, previousUserInfo
+	})
+	return fake
+}
+
+// callback opens the flow's redirect URI like the browser does after consent
+func callback(t *testing.T, redirectURI string, params url.Values) *http.Response {
+	t.Helper()
+	resp, err := http.Get(redirectURI + "?" + params.Encode())
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatalf("reading the callback page: %v", err)
+	}
+	return resp
+}
+
+func TestLoopbackFlow(t *testing.T) {
+	useTestSecretStore(t)
+	fake := newFakeGoogle(t)
+
+	flow, err := newLoopbackFlow(config.OAuth2Config{ClientID: "client-id", ClientSecret: "client-secret"})
+	if err != nil {
+		t.Fatalf("newLoopbackFlow: %v", err)
+	}
+
+	authURL, err := url.Parse(flow.authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL %q: %v", flow.authURL, err)
+	}
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != fake.URL+"/auth" {
+		t.Errorf("authorization URL points at %s, want %s/auth", got, fake.URL)
+	}
+	params := authURL.Query()
+	if got := params.Get("code_challenge_method"); got != "S256" {
+		t.Errorf("code_challenge_method = %q, want S256", got)
+	}
+	if got, want := params.Get("code_challenge"), oauth2.S256ChallengeFromVerifier(flow.verifier); got != want {
+		t.Errorf("code_challenge = %q, want %q", got, want)
+	}
+	if got := params.Get("state"); got == "" || got != flow.state {
+		t.Errorf("state = %q, want %q", got, flow.state)
+	}
+
+	redirectURI := params.Get("redirect_uri")
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		t.Fatalf("invalid redirect URI %q: %v", redirectURI, err)
+	}
+	if redirect.Scheme != "http" || redirect.Hostname() != "127.0.0.1" || redirect.Port() == "" || redirect.Path != "/callback" {
+		t.Errorf("redirect URI = %q, want http://127.0.0.1:<port>/callback", redirectURI)
+	}
+
+	// A callback with another state is turned away without ending the flow
+	resp := callback(t, redirectURI, url.Values{"state": {"forged"}, "code": {"forged-code"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("callback with mismatched state: status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	// The success page is served by the callback listener itself
+	resp = callback(t, redirectURI, url.Values{"state": {flow.state}, "code": {"auth-code"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("callback: status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("callback page Content-Type = %q, want text/html", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	account, err := flow.wait(ctx)
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if account.ID != "user-1" || account.Email != "user@example.com" {
+		t.Errorf("account = %s <%s>, want user-1 <user@example.com>", account.ID, account.Email)
+	}
+	if len(account.MissingScopes) > 0 {
+		t.Errorf("missing scopes %v, want none", account.MissingScopes)
+	}
+
+	fake.mu.Lock()
+	requests := fake.tokenRequests
+	fake.mu.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("%d token requests, want 1", len(requests))
+	}
+	exchange := requests[0]
+	if got := exchange.Get("code"); got != "auth-code" {
+		t.Errorf("exchanged code %q, want auth-code", got)
+	}
+	if got := exchange.Get("code_verifier"); got != flow.verifier {
+		t.Errorf("code_verifier = %q, want %q", got, flow.verifier)
+	}
+	if got := exchange.Get("redirect_uri"); got != redirectURI {
+		t.Errorf("token exchange redirect_uri = %q, want %q", got, redirectURI)
+	}
+	if got := exchange.Get("grant_type"); got != "authorization_code" {
+		t.Errorf("grant_type = %q, want authorization_code", got)
+	}
+
+	token, err := config.GetToken("user-1")
+	if err != nil {
+		t.Fatalf("token was not stored: %v", err)
+	}
+	if token.RefreshToken != "refresh-token" {
+		t.Errorf("stored refresh token %q, want refresh-token", token.RefreshToken)
+	}
+
+	// The listener is closed once the flow is over
+	if _, err := http.Get(redirectURI); err == nil {
+		t.Error("callback listener still accepts connections after the flow")
+	}
+}
+
+func TestLoopbackFlowAuthorizationDenied(t *testing.T) {
+	useTestSecretStore(t)
+	newFakeGoogle(t)
+
+	flow, err := newLoopbackFlow(config.OAuth2Config{ClientID: "client-id", ClientSecret: "client-secret"})
+	if err != nil {
+		t.Fatalf("newLoopbackFlow: %v", err)
+	}
+	resp := callback(t, flow.conf.RedirectURL, url.Values{"state": {flow.state}, "error": {"access_denied"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := flow.wait(ctx); err == nil || !strings.Contains(err.Error(), "access_denied") {
+		t.Errorf("wait error = %v, want access_denied", err)
+	}
+}
+
+// TestLoopbackFlowRejectedAccount signs in to an account the flow does not
+// accept, as when signing in again to another account, and checks that the
+// token of the account signed in to is left alone
+func TestLoopbackFlowRejectedAccount(t *testing.T) {
+	useTestSecretStore(t)
+	newFakeGoogle(t)
+
+	existing := &oauth2.Token{AccessToken: "existing-access", RefreshToken: "existing-refresh"}
+	if err := config.StoreToken("user-1", existing); err != nil {
+		t.Fatalf("StoreToken: %v", err)
+	}
+
+	flow, err := newLoopbackFlow(config.OAuth2Config{ClientID: "client-id", ClientSecret: "client-secret"})
+	if err != nil {
+		t.Fatalf("newLoopbackFlow: %v", err)
+	}
+	flow.accept = func(signedIn *config.Account) error {
+		return fmt.Errorf("signed in as %s instead of user-2", signedIn.ID)
+	}
+	callback(t, flow.conf.RedirectURL, url.Values{"state": {flow.state}, "code": {"auth-code"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := flow.wait(ctx); err == nil || !strings.Contains(err.Error(), "instead of user-2") {
+		t.Errorf("wait error = %v, want the rejection", err)
+	}
+
+	token, err := config.GetToken("user-1")
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if token.RefreshToken != "existing-refresh" {
+		t.Errorf("refresh token %q after a rejected sign-in, want existing-refresh", token.RefreshToken)
+	}
+}
+
+func TestRevokeAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{name: "revoked", status: http.StatusOK, body: `{}`},
+		{name: "unknown token", status: http.StatusBadRequest, body: `{"error": "invalid_token", "error_description": "Token expired or revoked"}`},
+		{name: "server error", status: http.StatusServiceUnavailable, body: `{"error": "backend_error"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestSecretStore(t)
+
+			var revoked []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				r.ParseForm()
+				revoked = append(revoked, r.PostForm.Get("token"))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			previousURL := revokeURL
+			revokeURL = server.URL
+			defer func() { revokeURL = previousURL }()
+
+			token := &oauth2.Token{AccessToken: "access-token", RefreshToken: "refresh-token"}
+			if err := config.StoreToken("user-1", token); err != nil {
+				t.Fatalf("StoreToken: %v", err)
+			}
+
+			err := RevokeAccount(context.Background(), "user-1")
+			if tt.wantErr && err == nil {
+				t.Error("RevokeAccount succeeded, want an error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("RevokeAccount: %v", err)
+			}
+
+			// The refresh token is revoked, which also ends its access tokens
+			if len(revoked) != 1 || revoked[0] != "refresh-token" {
+				t.Errorf("revoked %v, want [refresh-token]", revoked)
+			}
+
+			// The token is removed locally whatever Google answered
+			if _, err := config.GetToken("user-1"); err != config.ErrTokenNotFound {
+				t.Errorf("GetToken after revocation: %v, want ErrTokenNotFound", err)
+			}
+			if _, err := keyring.Get(config.ServiceName, config.TokenPrefix+"user-1"); err != keyring.ErrNotFound {
+				t.Errorf("keyring entry after revocation: %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestRevokeAccountWithoutToken(t *testing.T) {
+	useTestSecretStore(t)
+
+	previousURL := revokeURL
+	revokeURL = "http://127.0.0.1:1/revoke"
+	defer func() { revokeURL = previousURL }()
+
+	if err := RevokeAccount(context.Background(), "unknown"); err != nil {
+		t.Errorf("RevokeAccount without a token: %v", err)
+	}
+}
@@ -215,15 +215,17 @@ func (g *GnomeCalendarService) GetCalendars() ([]CalendarSource, error) {
 	return calendars, nil
 }
 
-// GetMeetings retrieves calendar events from Evolution Data Server
-func (g *GnomeCalendarService) GetMeetings(calendarIDs []string) ([]Meeting, error) {
+// GetMeetings retrieves calendar events from Evolution Data Server, and the
+// calendars that could not be read
+func (g *GnomeCalendarService) GetMeetings(calendarIDs []string) ([]Meeting, []string, error) {
 	if g.conn == nil {
 		if err := g.Connect(); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	var allMeetings []Meeting
+	var failedCalendars []string
 	
 	// Get time range for today
 	now := time.Now()
@@ -234,12 +236,13 @@ func (g *GnomeCalendarService) GetMeetings(calendarIDs []string) ([]Meeting, err
 		meetings, err := g.getMeetingsFromCalendar(calendarID, startOfDay, endOfDay)
 		if err != nil {
 			log.Printf("Failed to get meetings from calendar %s: %v", calendarID, err)
+			failedCalendars = append(failedCalendars, calendarID)
 			continue
 		}
 		allMeetings = append(allMeetings, meetings...)
 	}
 
-	return allMeetings, nil
+	return allMeetings, failedCalendars, nil
 }
 
 // getMeetingsFromCalendar retrieves events from a specific calendar
@@ -280,6 +283,7 @@ func (g *GnomeCalendarService) getMeetingsFromCalendar(calendarID string, start,
 			continue
 		}
 		if meeting != nil {
+			meeting.CalendarID = calendarID
 			meetings = append(meetings, *meeting)
 		}
 	}
@@ -311,7 +315,16 @@ func (g *GnomeCalendarService) parseCalendarObject(icalData string) (*Meeting, e
 			continue
 		}
 		
-		if strings.HasPrefix(line, "SUMMARY:") {
+		if strings.HasPrefix(line, "UID:") {
+			meeting.ID = strings.TrimPrefix(line, "UID:")
+		} else if strings.HasPrefix(line, "RECURRENCE-ID") {
+			// An occurrence of a recurring event, which shares the UID of the others
+			if colon := strings.Index(line, ":"); colon >= 0 {
+				meeting.RecurrenceID = line[colon+1:]
+			}
+		} else if line == "STATUS:CANCELLED" {
+			meeting.Cancelled = true
+		} else if strings.HasPrefix(line, "SUMMARY:") {
 			meeting.Title = strings.TrimPrefix(line, "SUMMARY:")
 		} else if strings.HasPrefix(line, "DTSTART:") {
 			timeStr := strings.TrimPrefix(line, "DTSTART:")
@@ -323,6 +336,10 @@ func (g *GnomeCalendarService) parseCalendarObject(icalData string) (*Meeting, e
 			if t, err := g.parseICalTime(timeStr); err == nil {
 				meeting.EndTime = t
 			}
+		} else if strings.HasPrefix(line, "ATTENDEE") {
+			if attendee := parseICalAttendee(line); attendee != "" {
+				meeting.Attendees = append(meeting.Attendees, attendee)
+			}
 		} else if strings.HasPrefix(line, "LOCATION:") {
 			location := strings.TrimPrefix(line, "LOCATION:")
 			if location != "" {
@@ -345,6 +362,32 @@ func (g *GnomeCalendarService) parseCalendarObject(icalData string) (*Meeting, e
 	return &meeting, nil
 }
 
+// parseICalAttendee extracts the common name, or the email address, from an
+// ATTENDEE property such as "ATTENDEE;CN=Jane Doe;PARTSTAT=ACCEPTED:mailto:jane@example.com"
+func parseICalAttendee(line string) string {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return ""
+	}
+	params, value := line[:colon], line[colon+1:]
+
+	for _, param := range strings.Split(params, ";") {
+		if strings.HasPrefix(param, "PARTSTAT=DECLINED") {
+			return ""
+		}
+	}
+	for _, param := range strings.Split(params, ";") {
+		if strings.HasPrefix(param, "CN=") {
+			return strings.Trim(strings.TrimPrefix(param, "CN="), `"`)
+		}
+	}
+
+	if len(value) >= len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		return value[len("mailto:"):]
+	}
+	return value
+}
+
 // parseICalTime parses iCalendar time format
 func (g *GnomeCalendarService) parseICalTime(timeStr string) (time.Time, error) {
 	// Handle different iCalendar time formats
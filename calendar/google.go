@@ -3,33 +3,45 @@ package calendar
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"meetingbar/config"
 
-	"golang.org/x/oauth2"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
 )
 
 type Meeting struct {
-	ID          string
-	Title       string
-	StartTime   time.Time
-	EndTime     time.Time
-	MeetingLink *MeetingLink
-	CalendarID  string
-	AccountID   string
-	IsAllDay    bool
+	ID string
+	// RecurrenceID tells apart occurrences of a recurring event that share an ID
+	RecurrenceID string
+	Title        string
+	StartTime    time.Time
+	EndTime      time.Time
+	MeetingLink  *MeetingLink
+	CalendarID   string
+	AccountID    string
+	IsAllDay     bool
+	Attendees    []string
+	// Cancelled is set for events the calendar marks as cancelled. They are
+	// only used to announce the cancellation, and may lack everything but the IDs.
+	Cancelled bool
+}
+
+// Key identifies the meeting, or the occurrence of a recurring one, across refreshes
+func (m Meeting) Key() string {
+	return m.ID + "|" + m.RecurrenceID
 }
 
 type GoogleCalendarService struct {
-	ctx context.Context
+	ctx   context.Context
+	store *config.Store
 }
 
-func NewGoogleCalendarService(ctx context.Context) *GoogleCalendarService {
-	return &GoogleCalendarService{ctx: ctx}
+func NewGoogleCalendarService(ctx context.Context, store *config.Store) *GoogleCalendarService {
+	return &GoogleCalendarService{ctx: ctx, store: store}
 }
 
 type CalendarInfo struct {
@@ -40,7 +52,7 @@ type CalendarInfo struct {
 }
 
 func (g *GoogleCalendarService) GetCalendars(accountID string) ([]config.Calendar, error) {
-	client, err := GetClientForAccount(g.ctx, accountID)
+	client, err := GetClientForAccount(g.ctx, g.store.Get(), accountID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client for account: %w", err)
 	}
@@ -69,24 +81,27 @@ func (g *GoogleCalendarService) GetCalendars(accountID string) ([]config.Calenda
 	return calendars, nil
 }
 
-func (g *GoogleCalendarService) GetMeetings(accountID string, enabledCalendars []string) ([]Meeting, error) {
-	client, err := GetClientForAccount(g.ctx, accountID)
+// GetMeetings returns the meetings of the next 24 hours, including the ones
+// marked cancelled, and the calendars that could not be read
+func (g *GoogleCalendarService) GetMeetings(accountID string, enabledCalendars []string) ([]Meeting, []string, error) {
+	client, err := GetClientForAccount(g.ctx, g.store.Get(), accountID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get client for account: %w", err)
+		return nil, nil, fmt.Errorf("failed to get client for account: %w", err)
 	}
 
 	service, err := calendar.NewService(g.ctx, option.WithHTTPClient(client))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create calendar service: %w", err)
+		return nil, nil, fmt.Errorf("failed to create calendar service: %w", err)
 	}
 
 	var allMeetings []Meeting
+	var failedCalendars []string
 	now := time.Now()
 	tomorrow := now.Add(24 * time.Hour)
 
 	for _, calendarID := range enabledCalendars {
 		events, err := service.Events.List(calendarID).
-			ShowDeleted(false).
+			ShowDeleted(true).
 			SingleEvents(true).
 			TimeMin(now.Format(time.RFC3339)).
 			TimeMax(tomorrow.Format(time.RFC3339)).
@@ -94,12 +109,17 @@ func (g *GoogleCalendarService) GetMeetings(accountID string, enabledCalendars [
 			Do()
 
 		if err != nil {
-			// Log error but continue with other calendars
-			fmt.Printf("Warning: failed to get events for calendar %s: %v\n", calendarID, err)
+			// Continue with the other calendars, and report this one as not read
+			log.Printf("Warning: failed to get events for calendar %s: %v", calendarID, err)
+			failedCalendars = append(failedCalendars, calendarID)
 			continue
 		}
 
 		for _, event := range events.Items {
+			if event.Status == "cancelled" {
+				allMeetings = append(allMeetings, cancelledMeeting(event, calendarID, accountID))
+				continue
+			}
 			meeting := g.convertEventToMeeting(event, calendarID, accountID)
 			if meeting != nil {
 				allMeetings = append(allMeetings, *meeting)
@@ -107,7 +127,23 @@ func (g *GoogleCalendarService) GetMeetings(accountID string, enabledCalendars [
 		}
 	}
 
-	return allMeetings, nil
+	return allMeetings, failedCalendars, nil
+}
+
+// cancelledMeeting records an event marked cancelled. Google only promises the
+// ID of such events, so the rest is taken from the previous refresh.
+func cancelledMeeting(event *calendar.Event, calendarID, accountID string) Meeting {
+	meeting := Meeting{
+		ID:         event.Id,
+		Title:      event.Summary,
+		CalendarID: calendarID,
+		AccountID:  accountID,
+		Cancelled:  true,
+	}
+	if event.Start != nil && event.Start.DateTime != "" {
+		meeting.StartTime, _ = time.Parse(time.RFC3339, event.Start.DateTime)
+	}
+	return meeting
 }
 
 func (g *GoogleCalendarService) convertEventToMeeting(event *calendar.Event, calendarID, accountID string) *Meeting {
@@ -199,42 +235,36 @@ func (g *GoogleCalendarService) convertEventToMeeting(event *calendar.Event, cal
 		CalendarID:  calendarID,
 		AccountID:   accountID,
 		IsAllDay:    isAllDay,
+		Attendees:   convertAttendees(event.Attendees),
 	}
 }
 
+// convertAttendees returns display names (or emails) of the people invited,
+// leaving out rooms and attendees who declined
+func convertAttendees(attendees []*calendar.EventAttendee) []string {
+	var names []string
+	for _, attendee := range attendees {
+		if attendee == nil || attendee.Resource || attendee.ResponseStatus == "declined" {
+			continue
+		}
+		name := attendee.DisplayName
+		if name == "" {
+			name = attendee.Email
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (g *GoogleCalendarService) GetAccountEmail(accountID string) (string, error) {
 	// This would require additional API call to get user info
 	// For now, return empty string and handle in UI
 	return "", nil
 }
 
-// GetAuthURL generates an OAuth2 authorization URL
-func (g *GoogleCalendarService) GetAuthURL() (string, error) {
-	// Load config to get OAuth2 credentials
-	cfg, err := config.Load()
-	if err != nil {
-		return "", fmt.Errorf("failed to load config: %w", err)
-	}
-	
-	if cfg.OAuth2.ClientID == "" || cfg.OAuth2.ClientSecret == "" {
-		return "", fmt.Errorf("OAuth2 credentials not configured")
-	}
-	
-	// Update global OAuth2 config
-	SetOAuth2Config(cfg.OAuth2.ClientID, cfg.OAuth2.ClientSecret)
-	
-	// Generate state for security
-	state, err := generateState()
-	if err != nil {
-		return "", fmt.Errorf("failed to generate state: %w", err)
-	}
-	
-	// Generate authorization URL
-	authURL := oauth2Config.AuthCodeURL(state, oauth2.AccessTypeOffline)
-	return authURL, nil
-}
-
-// RemoveAccount removes stored tokens for an account
+// RemoveAccount revokes the account's access at Google and removes its stored token
 func (g *GoogleCalendarService) RemoveAccount(accountID string) error {
-	return config.RemoveToken(accountID)
+	return RevokeAccount(g.ctx, accountID)
 }
\ No newline at end of file
@@ -10,31 +10,34 @@ import (
 
 // CalendarService defines the interface for calendar backends
 type CalendarService interface {
-	GetMeetings(accountID string, enabledCalendars []string) ([]Meeting, error)
+	// GetMeetings also returns the calendars that could not be read
+	GetMeetings(accountID string, enabledCalendars []string) ([]Meeting, []string, error)
 	GetCalendars(accountID string) ([]config.Calendar, error)
 }
 
 // UnifiedCalendarService manages multiple calendar backends
 type UnifiedCalendarService struct {
 	ctx              context.Context
-	config           *config.Config
+	store            *config.Store
 	googleService    *GoogleCalendarService
 	gnomeService     *GnomeCalendarService
 }
 
-// NewUnifiedCalendarService creates a new unified calendar service
-func NewUnifiedCalendarService(ctx context.Context, cfg *config.Config) *UnifiedCalendarService {
+// NewUnifiedCalendarService creates a new unified calendar service. It follows
+// the calendar backend selected in store.
+func NewUnifiedCalendarService(ctx context.Context, store *config.Store) *UnifiedCalendarService {
 	return &UnifiedCalendarService{
 		ctx:           ctx,
-		config:        cfg,
-		googleService: NewGoogleCalendarService(ctx),
+		store:         store,
+		googleService: NewGoogleCalendarService(ctx, store),
 		gnomeService:  NewGnomeCalendarService(ctx),
 	}
 }
 
-// GetMeetings retrieves meetings from the configured backend
-func (u *UnifiedCalendarService) GetMeetings(accountID string, enabledCalendars []string) ([]Meeting, error) {
-	switch u.config.CalendarBackend {
+// GetMeetings retrieves meetings from the configured backend, and the IDs of
+// the calendars that could not be read. Meetings marked cancelled are included.
+func (u *UnifiedCalendarService) GetMeetings(accountID string, enabledCalendars []string) ([]Meeting, []string, error) {
+	switch u.store.CalendarBackend() {
 	case "google":
 		return u.googleService.GetMeetings(accountID, enabledCalendars)
 	case "gnome":
@@ -43,7 +46,7 @@ func (u *UnifiedCalendarService) GetMeetings(accountID string, enabledCalendars
 			// Get all available calendars if none specified
 			calendars, err := u.GetGnomeCalendars()
 			if err != nil {
-				return nil, fmt.Errorf("failed to get GNOME calendars: %w", err)
+				return nil, nil, fmt.Errorf("failed to get GNOME calendars: %w", err)
 			}
 			var calendarIDs []string
 			for _, cal := range calendars {
@@ -55,19 +58,19 @@ func (u *UnifiedCalendarService) GetMeetings(accountID string, enabledCalendars
 		}
 		return u.gnomeService.GetMeetings(enabledCalendars)
 	default:
-		return nil, fmt.Errorf("unsupported calendar backend: %s", u.config.CalendarBackend)
+		return nil, nil, fmt.Errorf("unsupported calendar backend: %s", u.store.CalendarBackend())
 	}
 }
 
 // GetCalendars retrieves available calendars from the configured backend
 func (u *UnifiedCalendarService) GetCalendars(accountID string) ([]config.Calendar, error) {
-	switch u.config.CalendarBackend {
+	switch u.store.CalendarBackend() {
 	case "google":
 		return u.googleService.GetCalendars(accountID)
 	case "gnome":
 		return u.GetGnomeCalendars()
 	default:
-		return nil, fmt.Errorf("unsupported calendar backend: %s", u.config.CalendarBackend)
+		return nil, fmt.Errorf("unsupported calendar backend: %s", u.store.CalendarBackend())
 	}
 }
 
@@ -94,22 +97,22 @@ func (u *UnifiedCalendarService) GetGnomeCalendars() ([]config.Calendar, error)
 
 // IsGoogleBackend returns true if using Google Calendar backend
 func (u *UnifiedCalendarService) IsGoogleBackend() bool {
-	return u.config.CalendarBackend == "google"
+	return u.store.CalendarBackend() == "google"
 }
 
 // IsGnomeBackend returns true if using GNOME Calendar backend
 func (u *UnifiedCalendarService) IsGnomeBackend() bool {
-	return u.config.CalendarBackend == "gnome"
+	return u.store.CalendarBackend() == "gnome"
 }
 
 // RequiresAuthentication returns true if the backend requires OAuth authentication
 func (u *UnifiedCalendarService) RequiresAuthentication() bool {
-	return u.config.CalendarBackend == "google"
+	return u.store.CalendarBackend() == "google"
 }
 
 // GetBackendName returns the human-readable name of the current backend
 func (u *UnifiedCalendarService) GetBackendName() string {
-	switch u.config.CalendarBackend {
+	switch u.store.CalendarBackend() {
 	case "google":
 		return "Google Calendar"
 	case "gnome":
@@ -121,10 +124,10 @@ func (u *UnifiedCalendarService) GetBackendName() string {
 
 // TestConnection tests the connection to the configured backend
 func (u *UnifiedCalendarService) TestConnection() error {
-	switch u.config.CalendarBackend {
+	switch u.store.CalendarBackend() {
 	case "google":
 		// For Google, we need at least one account configured
-		if len(u.config.Accounts) == 0 {
+		if len(u.store.Accounts()) == 0 {
 			return fmt.Errorf("no Google accounts configured")
 		}
 		// Could add more specific Google API connectivity test here
@@ -143,21 +146,13 @@ func (u *UnifiedCalendarService) TestConnection() error {
 		
 		return nil
 	default:
-		return fmt.Errorf("unsupported calendar backend: %s", u.config.CalendarBackend)
+		return fmt.Errorf("unsupported calendar backend: %s", u.store.CalendarBackend())
 	}
 }
 
-// GetAuthURL returns OAuth2 authorization URL (Google backend only)
-func (u *UnifiedCalendarService) GetAuthURL() (string, error) {
-	if u.config.CalendarBackend != "google" {
-		return "", fmt.Errorf("GetAuthURL is only available for Google Calendar backend")
-	}
-	return u.googleService.GetAuthURL()
-}
-
 // RemoveAccount removes an account (Google backend only)
 func (u *UnifiedCalendarService) RemoveAccount(accountID string) error {
-	if u.config.CalendarBackend != "google" {
+	if u.store.CalendarBackend() != "google" {
 		return fmt.Errorf("RemoveAccount is only available for Google Calendar backend")
 	}
 	return u.googleService.RemoveAccount(accountID)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 
@@ -10,6 +11,15 @@ import (
 )
 
 func main() {
+	configDir := flag.String("config", "", "use this directory for config.json instead of ~/.config/meetingbar (also $"+config.ConfigDirEnv+")")
+	flag.Parse()
+
+	if *configDir != "" {
+		if err := config.SetConfigDir(*configDir); err != nil {
+			log.Fatalf("Invalid config directory: %v", err)
+		}
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -20,7 +30,7 @@ func main() {
 	ctx := context.Background()
 	
 	// Create GTK settings manager - this runs in separate process to avoid conflicts
-	settingsMgr := gtk.NewGTKSettingsManager(cfg, ctx, nil)
+	settingsMgr := gtk.NewGTKSettingsManager(config.NewStore(cfg), ctx)
 	
 	// Show settings and block until closed
 	if err := settingsMgr.ShowSettingsBlocking(); err != nil {
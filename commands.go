@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"meetingbar/config"
+	"meetingbar/ui"
+)
+
+// trayCommand returns the command chosen with the flags for the tray, which
+// are handed to the running MeetingBar. At most one can be given.
+func trayCommand(openSettings, refresh, joinNext bool) (ui.Command, error) {
+	command := ui.CommandNone
+	for _, choice := range []struct {
+		set     bool
+		command ui.Command
+	}{
+		{openSettings, ui.CommandOpenSettings},
+		{refresh, ui.CommandRefresh},
+		{joinNext, ui.CommandJoinNext},
+	} {
+		if !choice.set {
+			continue
+		}
+		if command != ui.CommandNone {
+			return ui.CommandNone, fmt.Errorf("--%s and --%s cannot be used together", command, choice.command)
+		}
+		command = choice.command
+	}
+	return command, nil
+}
+
+// switchProfile saves the chosen profile
+func switchProfile(cfg *config.Config, name string) error {
+	if name == config.AutomaticProfile {
+		name = ""
+	}
+	if err := cfg.SelectProfile(name); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	if current := cfg.CurrentProfile(time.Now()); current != "" {
+		fmt.Printf("Profile in effect: %s\n", current)
+	} else {
+		fmt.Println("No profile in effect")
+	}
+	return nil
+}
+
+// exportSettings writes the settings bundle to path, in TOML for a .toml file
+// and in JSON otherwise. The client secrets are included when a bundle
+// passphrase is set in the environment.
+func exportSettings(cfg *config.Config, path string) error {
+	format := config.BundleFormatJSON
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		format = config.BundleFormatTOML
+	}
+
+	passphrase := os.Getenv(config.BundlePassphraseEnv)
+	data, err := cfg.ExportBundle(format, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if passphrase != "" {
+		fmt.Printf("Exported settings with encrypted client secrets to %s\n", path)
+	} else {
+		fmt.Printf("Exported settings to %s (without client secrets; set %s to include them)\n", path, config.BundlePassphraseEnv)
+	}
+	return nil
+}
+
+// importSettings shows what importing the bundle at path changes and saves the
+// result once confirmed
+func importSettings(cfg *config.Config, path, mode string, assumeYes bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	bundle, err := config.ParseBundle(data)
+	if err != nil {
+		return err
+	}
+
+	passphrase := os.Getenv(config.BundlePassphraseEnv)
+	imported, changes, err := cfg.ImportBundle(bundle, mode, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if bundle.HasClientSecrets() && passphrase == "" {
+		fmt.Printf("The file contains client secrets; set %s to import them.\n", config.BundlePassphraseEnv)
+	}
+	if len(changes) == 0 {
+		fmt.Println("The file matches the current settings, nothing to import.")
+		return nil
+	}
+
+	fmt.Printf("Importing %s (%s) changes %d settings:\n", path, mode, len(changes))
+	for _, change := range changes {
+		fmt.Printf("  %s\n    - %s\n    + %s\n", change.Key, describeValue(change.Old), describeValue(change.New))
+	}
+
+	if !assumeYes && !confirm("Apply these changes?") {
+		fmt.Println("Nothing was changed.")
+		return nil
+	}
+	if err := imported.Save(); err != nil {
+		return err
+	}
+	fmt.Println("Settings imported.")
+	return nil
+}
+
+func describeValue(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return value
+}
+
+// confirm asks a yes/no question on the terminal; anything but yes is no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,439 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// BundleVersion is the layout version of settings bundles written by this version
+const BundleVersion = 1
+
+// BundlePassphraseEnv names the environment variable holding the passphrase
+// for the client secrets in a bundle, for the command line
+const BundlePassphraseEnv = "MEETINGBAR_BUNDLE_PASSPHRASE"
+
+// Bundle formats
+const (
+	BundleFormatJSON = "json"
+	BundleFormatTOML = "toml"
+)
+
+// Import modes
+const (
+	ImportMerge   = "merge"   // settings in the bundle replace the current ones, the others are kept
+	ImportReplace = "replace" // settings missing from the bundle are reset to their defaults
+)
+
+const bundleAdditionalData = "meetingbar-bundle-v1"
+
+// bundleExcludedKeys describe this installation rather than a setup to share,
+// so they are neither exported nor imported. Tokens are never part of the
+// settings in the first place.
+var bundleExcludedKeys = []string{"schema_version", "accounts", "active_profile", "secret_store"}
+
+// ErrWrongBundlePassphrase is returned when the client secrets of a bundle
+// cannot be decrypted with the given passphrase
+var ErrWrongBundlePassphrase = errors.New("wrong passphrase for the client secrets in the bundle")
+
+// Bundle is a portable copy of the settings, for setting up MeetingBar on
+// another machine or for a teammate. It holds no accounts or tokens, and the
+// OAuth client secrets only when encrypted with a passphrase.
+type Bundle struct {
+	Version       int                    `json:"meetingbar_bundle" toml:"meetingbar_bundle"`
+	SchemaVersion int                    `json:"schema_version" toml:"schema_version"`
+	ExportedAt    time.Time              `json:"exported_at" toml:"exported_at"`
+	Settings      map[string]interface{} `json:"settings" toml:"settings"`
+	ClientSecrets *EncryptedSecrets      `json:"client_secrets,omitempty" toml:"client_secrets,omitempty"`
+}
+
+// EncryptedSecrets holds secrets encrypted with a key derived from a
+// passphrase, base64 encoded so they survive TOML
+type EncryptedSecrets struct {
+	Salt  string `json:"salt" toml:"salt"`
+	Nonce string `json:"nonce" toml:"nonce"`
+	Data  string `json:"data" toml:"data"`
+}
+
+// SettingChange is a setting that an import changes. Old and New are the
+// values as JSON, empty when the setting is not set.
+type SettingChange struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ExportBundle returns the settings as a bundle in the given format. With a
+// passphrase, the OAuth client secrets are included, encrypted with it.
+func (c *Config) ExportBundle(format, passphrase string) ([]byte, error) {
+	settings, err := c.shareableSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := Bundle{
+		Version:       BundleVersion,
+		SchemaVersion: CurrentSchemaVersion,
+		ExportedAt:    time.Now().UTC().Truncate(time.Second),
+		Settings:      settings,
+	}
+	if passphrase != "" {
+		if bundle.ClientSecrets, err = encryptSecrets(c.clientSecrets(), passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	switch format {
+	case BundleFormatJSON:
+		data, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode bundle: %w", err)
+		}
+		return append(data, '\n'), nil
+	case BundleFormatTOML:
+		data, err := toml.Marshal(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode bundle: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown bundle format %q, expected %s or %s", format, BundleFormatJSON, BundleFormatTOML)
+	}
+}
+
+// ParseBundle reads a bundle written by ExportBundle in either format and
+// upgrades settings from older versions
+func ParseBundle(data []byte) (*Bundle, error) {
+	var bundle Bundle
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(data, &bundle)
+	} else {
+		err = toml.Unmarshal(data, &bundle)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	if bundle.Version == 0 {
+		return nil, fmt.Errorf("not a MeetingBar settings bundle")
+	}
+	if bundle.Version > BundleVersion || bundle.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("the bundle was made by a newer version of MeetingBar")
+	}
+
+	settings, err := normalizeSettings(bundle.Settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := MigrateSettings(settings, bundle.SchemaVersion); err != nil {
+		return nil, err
+	}
+	for _, key := range bundleExcludedKeys {
+		delete(settings, key)
+	}
+	bundle.Settings = settings
+	return &bundle, nil
+}
+
+// HasClientSecrets reports whether the bundle holds encrypted client secrets
+func (b *Bundle) HasClientSecrets() bool {
+	return b.ClientSecrets != nil
+}
+
+// ImportBundle returns the settings that importing the bundle results in, and
+// the changes compared to c, which is left as it is. Accounts and the choices
+// that belong to this installation are kept. The client secrets in the bundle
+// are only used with a passphrase; otherwise those of unchanged clients are kept.
+func (c *Config) ImportBundle(b *Bundle, mode, passphrase string) (*Config, []SettingChange, error) {
+	current, err := c.shareableSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaults, err := NewConfig().shareableSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var settings map[string]interface{}
+	switch mode {
+	case ImportMerge:
+		settings = current
+	case ImportReplace:
+		settings = defaults
+	default:
+		return nil, nil, fmt.Errorf("unknown import mode %q, expected %s or %s", mode, ImportMerge, ImportReplace)
+	}
+	// Settings unknown to this version, e.g. from a newer one, are left out
+	for key, value := range b.Settings {
+		if _, known := defaults[key]; known {
+			settings[key] = value
+		}
+	}
+
+	imported := NewConfig()
+	if err := decodeSettings(settings, imported); err != nil {
+		return nil, nil, fmt.Errorf("failed to read bundle settings: %w", err)
+	}
+	imported.Accounts = cloneAccounts(c.Accounts)
+	imported.SecretStore = c.SecretStore
+	if _, ok := imported.Profile(c.ActiveProfile); ok {
+		imported.ActiveProfile = c.ActiveProfile
+	}
+
+	var secrets map[string]string
+	if b.ClientSecrets != nil && passphrase != "" {
+		if secrets, err = decryptSecrets(b.ClientSecrets, passphrase); err != nil {
+			return nil, nil, err
+		}
+	}
+	imported.restoreClientSecrets(c, secrets)
+	// Read-only settings are kept, so the preview does not list them
+	imported.applyReadOnly()
+
+	if err := imported.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("the bundle contains %w", err)
+	}
+
+	changes, err := c.changesTo(imported)
+	if err != nil {
+		return nil, nil, err
+	}
+	return imported, changes, nil
+}
+
+// restoreClientSecrets takes the client secrets from the bundle's secrets,
+// or from previous for clients whose ID did not change
+func (c *Config) restoreClientSecrets(previous *Config, secrets map[string]string) {
+	if secret, ok := secrets[ClientSecretKey]; ok {
+		c.OAuth2.ClientSecret = secret
+	} else if c.OAuth2.ClientID == previous.OAuth2.ClientID {
+		c.OAuth2.ClientSecret = previous.OAuth2.ClientSecret
+	}
+
+	for i := range c.OAuthClients {
+		client := &c.OAuthClients[i]
+		if secret, ok := secrets[client.secretKey()]; ok {
+			client.ClientSecret = secret
+			continue
+		}
+		for _, old := range previous.OAuthClients {
+			if old.Name == client.Name && old.ClientID == client.ClientID {
+				client.ClientSecret = old.ClientSecret
+			}
+		}
+	}
+}
+
+// changesTo lists the shareable settings and client secrets that differ
+// between c and other, sorted by key
+func (c *Config) changesTo(other *Config) ([]SettingChange, error) {
+	before, err := c.shareableSettings()
+	if err != nil {
+		return nil, err
+	}
+	after, err := other.shareableSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []SettingChange
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			continue
+		}
+		changes = append(changes, SettingChange{Key: key, Old: settingJSON(before[key]), New: settingJSON(value)})
+	}
+
+	// Secrets are only named, never shown
+	oldSecrets := c.clientSecrets()
+	for key, secret := range other.clientSecrets() {
+		if secret == oldSecrets[key] {
+			continue
+		}
+		change := SettingChange{Key: secretLabel(key), New: `"(hidden)"`}
+		if oldSecrets[key] != "" {
+			change.Old = `"(hidden)"`
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// secretLabel names a client secret by the setting it belongs to, e.g. "oauth_clients[work].client_secret"
+func secretLabel(key string) string {
+	if name := strings.TrimPrefix(key, ClientSecretKey+"/"); name != key {
+		return fmt.Sprintf("oauth_clients[%s].client_secret", name)
+	}
+	return "oauth2.client_secret"
+}
+
+// shareableSettings returns the settings that bundles carry, as plain JSON values
+func (c *Config) shareableSettings() (map[string]interface{}, error) {
+	settings, err := normalizeSettings(c.settings())
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range bundleExcludedKeys {
+		delete(settings, key)
+	}
+	return settings, nil
+}
+
+// clientSecrets returns the secrets of the configured OAuth clients by secret store key
+func (c *Config) clientSecrets() map[string]string {
+	secrets := make(map[string]string)
+	if c.OAuth2.ClientSecret != "" {
+		secrets[ClientSecretKey] = c.OAuth2.ClientSecret
+	}
+	for _, client := range c.OAuthClients {
+		if client.ClientSecret != "" {
+			secrets[client.secretKey()] = client.ClientSecret
+		}
+	}
+	return secrets
+}
+
+// normalizeSettings turns settings into the values JSON decodes to, with whole
+// numbers as int64 and without nulls, so they compare equal however they were
+// read and can be written as TOML
+func normalizeSettings(settings map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+	if normalized == nil {
+		normalized = make(map[string]interface{})
+	}
+	return normalizeValue(normalized).(map[string]interface{}), nil
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, entry := range v {
+			if entry == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = normalizeValue(entry)
+		}
+	case []interface{}:
+		for i, entry := range v {
+			v[i] = normalizeValue(entry)
+		}
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	}
+	return value
+}
+
+// decodeSettings decodes settings keyed as in config.json into cfg, like Load does
+func decodeSettings(settings map[string]interface{}, cfg *Config) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			mapstructure.StringToTimeHookFunc(time.RFC3339),
+		),
+		WeaklyTypedInput: true,
+		ZeroFields:       true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(settings)
+}
+
+func settingJSON(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// encryptSecrets encrypts secrets with a key derived from passphrase
+func encryptSecrets(secrets map[string]string, passphrase string) (*EncryptedSecrets, error) {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := passphraseCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return &EncryptedSecrets{
+		Salt:  base64.StdEncoding.EncodeToString(salt),
+		Nonce: base64.StdEncoding.EncodeToString(nonce),
+		Data:  base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, []byte(bundleAdditionalData))),
+	}, nil
+}
+
+// decryptSecrets reverses encryptSecrets
+func decryptSecrets(encrypted *EncryptedSecrets, passphrase string) (map[string]string, error) {
+	var salt, nonce, data []byte
+	for _, field := range []struct {
+		value string
+		out   *[]byte
+	}{{encrypted.Salt, &salt}, {encrypted.Nonce, &nonce}, {encrypted.Data, &data}} {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(field.value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client secrets in bundle: %w", err)
+		}
+		*field.out = decoded
+	}
+
+	gcm, err := passphraseCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid client secrets in bundle: bad nonce")
+	}
+	plaintext, err := gcm.Open(nil, nonce, data, []byte(bundleAdditionalData))
+	if err != nil {
+		return nil, ErrWrongBundlePassphrase
+	}
+
+	var secrets map[string]string
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid client secrets in bundle: %w", err)
+	}
+	return secrets, nil
+}
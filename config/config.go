@@ -1,11 +1,15 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -18,28 +22,112 @@ type Config struct {
 	ShowMeetingLinks        bool         `mapstructure:"show_meeting_links"`
 	PersistentNotifications bool         `mapstructure:"persistent_notifications"`
 	NotificationSound       bool         `mapstructure:"notification_sound"`
+	SoundFiles              map[string]string `mapstructure:"sound_files"` // custom sound file per reminder type
+	NotifyRescheduled       bool         `mapstructure:"notify_rescheduled"`
+	NotifyCancelled         bool         `mapstructure:"notify_cancelled"`
+	NotifyLinkChanged       bool         `mapstructure:"notify_link_changed"`
+	NotifyNewMeetings       bool         `mapstructure:"notify_new_meetings"`
+	NotifyMeetingEnding     bool         `mapstructure:"notify_meeting_ending"`
+	MeetingEndingTime       int          `mapstructure:"meeting_ending_time"` // minutes before meeting ends
+	NotifyOverlap           bool         `mapstructure:"notify_overlap"`
+	InterruptiveReminders   bool         `mapstructure:"interruptive_reminders"`
+	QuietHours              []QuietHours `mapstructure:"quiet_hours"`
+	QuietMode               string       `mapstructure:"quiet_mode"` // "silent" or "suppress"
+	RespectDoNotDisturb     bool         `mapstructure:"respect_do_not_disturb"`
+	AlwaysAlert             []AlertRule  `mapstructure:"always_alert"`
 	ShowDuration            bool         `mapstructure:"show_duration"`
 	MaxMeetings             int          `mapstructure:"max_meetings"`
 	MaxTitleLength          int          `mapstructure:"max_title_length"`
 	CurrentMeetingFormat    string       `mapstructure:"current_meeting_format"`
 	UpcomingMeetingFormat   string       `mapstructure:"upcoming_meeting_format"`
-	StartWithSystem         bool         `mapstructure:"start_with_system"`
+	Profiles                []Profile     `mapstructure:"profiles"`
+	ActiveProfile           string        `mapstructure:"active_profile"` // empty means switching by ProfileSchedule
+	ProfileSchedule         []ProfileRule `mapstructure:"profile_schedule"`
 	AutoRefreshStartup      bool         `mapstructure:"auto_refresh_startup"`
 	LaunchAtLogin           bool         `mapstructure:"launch_at_login"`
+	AutostartMethod         string       `mapstructure:"autostart_method"` // "desktop" or "systemd"
 	Debug                   bool         `mapstructure:"debug"`
 	CalendarBackend         string       `mapstructure:"calendar_backend"` // "google" or "gnome"
+	SecretStore             string       `mapstructure:"secret_store"` // "auto", "keyring" or "file"
 	OAuth2                  OAuth2Config `mapstructure:"oauth2"`
+	OAuthClients            []OAuthClient `mapstructure:"oauth_clients"`
 }
 
 type OAuth2Config struct {
-	ClientID     string `mapstructure:"ClientID" json:"ClientID"`
-	ClientSecret string `mapstructure:"ClientSecret" json:"ClientSecret"`
+	ClientID string `mapstructure:"client_id" json:"client_id"`
+	// ClientSecret lives in the secret store; the tag keeps it out of config.json and any JSON dump
+	ClientSecret string `mapstructure:"client_secret" json:"-"`
+}
+
+// OAuthClient is an additional OAuth client, for accounts that have to use a
+// different client than the default one, e.g. one approved by a Workspace admin
+type OAuthClient struct {
+	Name     string `mapstructure:"name" json:"name"`
+	ClientID string `mapstructure:"client_id" json:"client_id"`
+	// ClientSecret lives in the secret store like the default client's
+	ClientSecret string `mapstructure:"client_secret" json:"-"`
+}
+
+// ClientSecretKey is the secret store key of the default OAuth2 client secret
+const ClientSecretKey = "oauth2_client_secret"
+
+// secretKey is the secret store key of the client's secret
+func (o OAuthClient) secretKey() string {
+	return ClientSecretKey + "/" + o.Name
+}
+
+// String redacts the client secret, so configs can be logged safely
+func (o OAuthClient) String() string {
+	secret := ""
+	if o.ClientSecret != "" {
+		secret = "[REDACTED]"
+	}
+	return fmt.Sprintf("{Name:%s ClientID:%s ClientSecret:%s}", o.Name, o.ClientID, secret)
+}
+
+// savedClientSecrets holds the client secrets last read from or written to the
+// secret store, by secret store key
+var savedClientSecrets = make(map[string]string)
+
+// String redacts the client secret, so configs can be logged safely
+func (o OAuth2Config) String() string {
+	secret := ""
+	if o.ClientSecret != "" {
+		secret = "[REDACTED]"
+	}
+	return fmt.Sprintf("{ClientID:%s ClientSecret:%s}", o.ClientID, secret)
+}
+
+// QuietHours is a recurring period during which reminders are downgraded
+type QuietHours struct {
+	Days      []string `mapstructure:"days" json:"days"`           // "mon" to "sun"; empty means every day
+	Start     string   `mapstructure:"start" json:"start"`         // "15:04"
+	End       string   `mapstructure:"end" json:"end"`             // before Start for periods spanning midnight
+	Calendars []string `mapstructure:"calendars" json:"calendars"` // calendar IDs; empty means all calendars
+}
+
+// AlertRule matches meetings that are announced even during quiet hours or Do Not Disturb.
+// A meeting matches when it matches every field that is set; a rule with no fields set
+// matches nothing, and Validate rejects it.
+type AlertRule struct {
+	TitleContains string `mapstructure:"title_contains" json:"title_contains"`
+	CalendarID    string `mapstructure:"calendar_id" json:"calendar_id"`
 }
 
 type Account struct {
-	ID      string    `mapstructure:"id"`
-	Email   string    `mapstructure:"email"`
-	AddedAt time.Time `mapstructure:"added_at"`
+	ID      string    `mapstructure:"id" json:"id"`
+	Email   string    `mapstructure:"email" json:"email"`
+	AddedAt time.Time `mapstructure:"added_at" json:"added_at"`
+
+	// NeedsReauth is set when the stored credentials stopped working; AuthError says why
+	NeedsReauth bool   `mapstructure:"needs_reauth" json:"needs_reauth,omitempty"`
+	AuthError   string `mapstructure:"auth_error" json:"auth_error,omitempty"`
+
+	// OAuthClient names the entry of oauth_clients used for this account; empty means the default client
+	OAuthClient string `mapstructure:"oauth_client" json:"oauth_client,omitempty"`
+
+	// MissingScopes lists the scopes left unchecked at sign-in; it is not saved
+	MissingScopes []string `mapstructure:"-" json:"-"`
 }
 
 type Calendar struct {
@@ -57,18 +145,49 @@ const (
 	DefaultShowMeetingLinks         = true
 	DefaultPersistentNotifications  = false
 	DefaultNotificationSound        = true
+	DefaultNotifyRescheduled        = true
+	DefaultNotifyCancelled          = true
+	DefaultNotifyLinkChanged        = true
+	DefaultNotifyNewMeetings        = true
+	DefaultNotifyMeetingEnding      = false
+	DefaultMeetingEndingTime        = 5     // minutes
+	DefaultNotifyOverlap            = true
+	DefaultInterruptiveReminders    = false
+	DefaultQuietMode                = QuietModeSilent
+	DefaultRespectDoNotDisturb      = true
 	DefaultShowDuration             = false
 	DefaultMaxMeetings              = 5
 	DefaultMaxTitleLength           = 25
 	DefaultCurrentMeetingFormat     = "{title} {time_left} left"
 	DefaultUpcomingMeetingFormat    = "{title} in {time_until}"
-	DefaultStartWithSystem          = false
 	DefaultAutoRefreshStartup       = true
 	DefaultLaunchAtLogin            = false
+	DefaultAutostartMethod          = AutostartDesktop
 	DefaultCalendarBackend          = "google"
+	DefaultSecretStore              = SecretStoreAuto
+)
+
+// Autostart methods decide how MeetingBar is started at login
+const (
+	AutostartDesktop = "desktop" // an XDG autostart entry
+	AutostartSystemd = "systemd" // a systemd user service, restarted if it fails
+)
+
+// Quiet modes decide what happens to reminders during quiet hours or Do Not Disturb
+const (
+	QuietModeSilent   = "silent"   // no sound, low urgency
+	QuietModeSuppress = "suppress" // not shown at all
 )
 
 func Load() (*Config, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return load()
+}
+
+func load() (*Config, error) {
+	// Values left from an earlier load would take precedence over the files
+	viper.Reset()
 	viper.SetConfigName("config")
 	viper.SetConfigType("json")
 	
@@ -79,6 +198,15 @@ func Load() (*Config, error) {
 	
 	viper.AddConfigPath(configDir)
 	
+	// Upgrade files written by older versions before viper reads them
+	if err := migrateConfigFile(filepath.Join(configDir, configFileName)); err != nil {
+		return nil, fmt.Errorf("failed to migrate config file: %w", err)
+	}
+	
+	// Settings are layered: the built-in defaults, the system-wide config, the
+	// user's config.json and then MEETINGBAR_* environment variables
+	system, systemSecrets, locked := loadSystemLayer()
+	
 	// Set defaults
 	viper.SetDefault("refresh_interval", DefaultRefreshInterval)
 	viper.SetDefault("notification_time", DefaultNotificationTime)
@@ -86,19 +214,40 @@ func Load() (*Config, error) {
 	viper.SetDefault("show_meeting_links", DefaultShowMeetingLinks)
 	viper.SetDefault("persistent_notifications", DefaultPersistentNotifications)
 	viper.SetDefault("notification_sound", DefaultNotificationSound)
+	viper.SetDefault("sound_files", map[string]string{})
+	viper.SetDefault("notify_rescheduled", DefaultNotifyRescheduled)
+	viper.SetDefault("notify_cancelled", DefaultNotifyCancelled)
+	viper.SetDefault("notify_link_changed", DefaultNotifyLinkChanged)
+	viper.SetDefault("notify_new_meetings", DefaultNotifyNewMeetings)
+	viper.SetDefault("notify_meeting_ending", DefaultNotifyMeetingEnding)
+	viper.SetDefault("meeting_ending_time", DefaultMeetingEndingTime)
+	viper.SetDefault("notify_overlap", DefaultNotifyOverlap)
+	viper.SetDefault("interruptive_reminders", DefaultInterruptiveReminders)
+	viper.SetDefault("quiet_hours", []QuietHours{})
+	viper.SetDefault("quiet_mode", DefaultQuietMode)
+	viper.SetDefault("respect_do_not_disturb", DefaultRespectDoNotDisturb)
+	viper.SetDefault("always_alert", []AlertRule{})
 	viper.SetDefault("show_duration", DefaultShowDuration)
 	viper.SetDefault("max_meetings", DefaultMaxMeetings)
 	viper.SetDefault("max_title_length", DefaultMaxTitleLength)
 	viper.SetDefault("current_meeting_format", DefaultCurrentMeetingFormat)
 	viper.SetDefault("upcoming_meeting_format", DefaultUpcomingMeetingFormat)
-	viper.SetDefault("start_with_system", DefaultStartWithSystem)
+	viper.SetDefault("profiles", []Profile{})
+	viper.SetDefault("active_profile", "")
+	viper.SetDefault("profile_schedule", []ProfileRule{})
 	viper.SetDefault("auto_refresh_startup", DefaultAutoRefreshStartup)
 	viper.SetDefault("launch_at_login", DefaultLaunchAtLogin)
+	viper.SetDefault("autostart_method", DefaultAutostartMethod)
 	viper.SetDefault("debug", false)
 	viper.SetDefault("calendar_backend", DefaultCalendarBackend)
+	viper.SetDefault("secret_store", DefaultSecretStore)
 	viper.SetDefault("accounts", []Account{})
 	viper.SetDefault("enabled_calendars", []string{})
-	viper.SetDefault("oauth2", OAuth2Config{})
+	viper.SetDefault("oauth2.client_id", "")
+	viper.SetDefault("oauth_clients", []OAuthClient{})
+	for key, value := range system {
+		viper.SetDefault(key, value)
+	}
 	
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -111,93 +260,301 @@ func Load() (*Config, error) {
 		}
 	}
 	
+	env := envSettings(viper.AllKeys())
+	for key, value := range env {
+		viper.Set(key, value)
+	}
+	
+	userFile, err := readUserFile(filepath.Join(configDir, configFileName))
+	if err != nil {
+		return nil, err
+	}
+	layersMu.Lock()
+	currentLayers = layers{
+		system:        system,
+		systemSecrets: systemSecrets,
+		locked:        locked,
+		env:           envSources(env),
+		userFile:      userFile,
+	}
+	layersMu.Unlock()
+	
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	))
+	if err := viper.Unmarshal(&config, decodeHook); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 	
+	config.enforceLocked()
+	
+	// A hand-edited file must not stop MeetingBar from starting
+	config.repair()
+	config.rememberEnforced()
+	
+	UseSecretStore(config.SecretStore)
+	if err := config.loadClientSecrets(); err != nil {
+		log.Printf("Failed to load OAuth2 client secrets: %v", err)
+	}
+	
 	return &config, nil
 }
 
 func (c *Config) Save() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	
 	if err := ensureConfigDir(); err != nil {
 		return fmt.Errorf("failed to ensure config directory: %w", err)
 	}
 	
-	viper.Set("accounts", c.Accounts)
-	viper.Set("enabled_calendars", c.EnabledCalendars)
-	viper.Set("refresh_interval", c.RefreshInterval)
-	viper.Set("notification_time", c.NotificationTime)
-	viper.Set("enable_notifications", c.EnableNotifications)
-	viper.Set("show_meeting_links", c.ShowMeetingLinks)
-	viper.Set("persistent_notifications", c.PersistentNotifications)
-	viper.Set("notification_sound", c.NotificationSound)
-	viper.Set("show_duration", c.ShowDuration)
-	viper.Set("max_meetings", c.MaxMeetings)
-	viper.Set("max_title_length", c.MaxTitleLength)
-	viper.Set("current_meeting_format", c.CurrentMeetingFormat)
-	viper.Set("upcoming_meeting_format", c.UpcomingMeetingFormat)
-	viper.Set("start_with_system", c.StartWithSystem)
-	viper.Set("auto_refresh_startup", c.AutoRefreshStartup)
-	viper.Set("launch_at_login", c.LaunchAtLogin)
-	viper.Set("debug", c.Debug)
-	viper.Set("calendar_backend", c.CalendarBackend)
-	viper.Set("oauth2", c.OAuth2)
-	
-	// Try to write config, if file doesn't exist use SafeWriteConfig
-	err := viper.WriteConfig()
+	if err := c.saveClientSecrets(); err != nil {
+		return err
+	}
+	return c.writeConfig()
+}
+
+// writeConfig writes the user's own settings to config.json. Callers hold configMu.
+func (c *Config) writeConfig() error {
+	settings, err := c.userSettings()
 	if err != nil {
-		// If WriteConfig fails (likely because no config file exists), try SafeWriteConfig
-		err = viper.SafeWriteConfig()
-		if err != nil {
-			return fmt.Errorf("failed to write config file: %w", err)
-		}
+		return err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	
+	configDir, err := getConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
 	}
+	if err := writeFileAtomic(filepath.Join(configDir, configFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	rememberWrite(data)
+	
+	layersMu.Lock()
+	currentLayers.userFile = settings
+	layersMu.Unlock()
 	return nil
 }
 
-func (c *Config) GetRefreshDuration() time.Duration {
-	return time.Duration(c.RefreshInterval) * time.Minute
+// writeFileAtomic replaces the file at path with data, so readers never see
+// it half written. An existing file keeps its permissions.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
-func (c *Config) GetNotificationDuration() time.Duration {
-	return time.Duration(c.NotificationTime) * time.Minute
+// loadClientSecrets reads the client secrets from the secret store. Secrets
+// still kept in config.json by older versions are moved to the store first.
+func (c *Config) loadClientSecrets() error {
+	moved := false
+	if err := loadClientSecret(ClientSecretKey, &c.OAuth2.ClientSecret, &moved); err != nil {
+		return err
+	}
+	for i := range c.OAuthClients {
+		client := &c.OAuthClients[i]
+		if err := loadClientSecret(client.secretKey(), &client.ClientSecret, &moved); err != nil {
+			return err
+		}
+	}
+	if !moved {
+		return nil
+	}
+	
+	if err := c.writeConfig(); err != nil {
+		return fmt.Errorf("failed to remove client secrets from config file: %w", err)
+	}
+	log.Printf("Moved the OAuth2 client secrets from config.json to the secret store")
+	return nil
 }
 
-func getConfigDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
+func loadClientSecret(key string, secret *string, moved *bool) error {
+	if *secret != "" {
+		if err := SetSecret(key, *secret); err != nil {
+			return fmt.Errorf("failed to move client secret to the secret store: %w", err)
+		}
+		savedClientSecrets[key] = *secret
+		*moved = true
+		return nil
+	}
+	
+	stored, err := GetSecret(key)
+	if errors.Is(err, ErrSecretNotFound) {
+		*secret = systemSecret(key)
+		return nil
+	}
 	if err != nil {
-		return "", err
+		return err
 	}
-	return filepath.Join(homeDir, ".config", "meetingbar"), nil
+	*secret = stored
+	savedClientSecrets[key] = stored
+	return nil
 }
 
-func GetCacheDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+// saveClientSecrets writes the client secrets that have changed to the secret
+// store and deletes those of removed clients
+func (c *Config) saveClientSecrets() error {
+	current := map[string]string{ClientSecretKey: c.OAuth2.ClientSecret}
+	for _, client := range c.OAuthClients {
+		current[client.secretKey()] = client.ClientSecret
+	}
+	for key := range savedClientSecrets {
+		if _, ok := current[key]; !ok {
+			current[key] = ""
+		}
+	}
+	
+	for key, secret := range current {
+		// The system config's secrets are not copied to the user's store
+		if secret != "" && secret == systemSecret(key) {
+			secret = ""
+		}
+		if secret == savedClientSecrets[key] {
+			continue
+		}
+		
+		var err error
+		if secret == "" {
+			err = DeleteSecret(key)
+		} else {
+			err = SetSecret(key, secret)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store client secret: %w", err)
+		}
+		if secret == "" {
+			delete(savedClientSecrets, key)
+		} else {
+			savedClientSecrets[key] = secret
+		}
 	}
-	return filepath.Join(homeDir, ".cache", "meetingbar"), nil
+	return nil
 }
 
-func ensureConfigDir() error {
-	configDir, err := getConfigDir()
-	if err != nil {
-		return err
+// settings returns the settings as they are written to config.json, by key.
+// The OAuth clients are included without their secrets, so a later Load does
+// not find them in plain text.
+func (c *Config) settings() map[string]interface{} {
+	return map[string]interface{}{
+		"schema_version":           CurrentSchemaVersion,
+		"accounts":                 c.Accounts,
+		"enabled_calendars":        c.EnabledCalendars,
+		"refresh_interval":         c.RefreshInterval,
+		"notification_time":        c.NotificationTime,
+		"enable_notifications":     c.EnableNotifications,
+		"show_meeting_links":       c.ShowMeetingLinks,
+		"persistent_notifications": c.PersistentNotifications,
+		"notification_sound":       c.NotificationSound,
+		"sound_files":              c.SoundFiles,
+		"notify_rescheduled":       c.NotifyRescheduled,
+		"notify_cancelled":         c.NotifyCancelled,
+		"notify_link_changed":      c.NotifyLinkChanged,
+		"notify_new_meetings":      c.NotifyNewMeetings,
+		"notify_meeting_ending":    c.NotifyMeetingEnding,
+		"meeting_ending_time":      c.MeetingEndingTime,
+		"notify_overlap":           c.NotifyOverlap,
+		"interruptive_reminders":   c.InterruptiveReminders,
+		"quiet_hours":              c.QuietHours,
+		"quiet_mode":               c.QuietMode,
+		"respect_do_not_disturb":   c.RespectDoNotDisturb,
+		"always_alert":             c.AlwaysAlert,
+		"show_duration":            c.ShowDuration,
+		"max_meetings":             c.MaxMeetings,
+		"max_title_length":         c.MaxTitleLength,
+		"current_meeting_format":   c.CurrentMeetingFormat,
+		"upcoming_meeting_format":  c.UpcomingMeetingFormat,
+		"profiles":                 c.Profiles,
+		"active_profile":           c.ActiveProfile,
+		"profile_schedule":         c.ProfileSchedule,
+		"auto_refresh_startup":     c.AutoRefreshStartup,
+		"launch_at_login":          c.LaunchAtLogin,
+		"autostart_method":         c.AutostartMethod,
+		"debug":                    c.Debug,
+		"calendar_backend":         c.CalendarBackend,
+		"secret_store":             c.SecretStore,
+		"oauth2":                   OAuth2Config{ClientID: c.OAuth2.ClientID},
+		"oauth_clients":            c.clientsWithoutSecrets(),
 	}
-	return os.MkdirAll(configDir, 0755)
 }
 
-func EnsureCacheDir() error {
-	cacheDir, err := GetCacheDir()
-	if err != nil {
-		return err
+func (c *Config) clientsWithoutSecrets() []OAuthClient {
+	clients := make([]OAuthClient, len(c.OAuthClients))
+	for i, client := range c.OAuthClients {
+		clients[i] = OAuthClient{Name: client.Name, ClientID: client.ClientID}
 	}
-	return os.MkdirAll(cacheDir, 0755)
+	return clients
 }
 
-// NewConfig creates a new config with default values
+// ClientCredentials returns the credentials of the OAuth client with the given
+// name, or of the default client when name is empty
+func (c *Config) ClientCredentials(name string) (OAuth2Config, error) {
+	if name == "" {
+		if c.OAuth2.ClientID == "" || c.OAuth2.ClientSecret == "" {
+			return OAuth2Config{}, fmt.Errorf("OAuth2 credentials not configured. Please set them in settings first")
+		}
+		return c.OAuth2, nil
+	}
+	
+	for _, client := range c.OAuthClients {
+		if client.Name != name {
+			continue
+		}
+		if client.ClientID == "" || client.ClientSecret == "" {
+			return OAuth2Config{}, fmt.Errorf("OAuth client %q has no client ID or secret", name)
+		}
+		return OAuth2Config{ClientID: client.ClientID, ClientSecret: client.ClientSecret}, nil
+	}
+	return OAuth2Config{}, fmt.Errorf("OAuth client %q not found", name)
+}
+
+func (c *Config) GetRefreshDuration() time.Duration {
+	return time.Duration(c.RefreshInterval) * time.Minute
+}
+
+func (c *Config) GetNotificationDuration() time.Duration {
+	return time.Duration(c.NotificationTime) * time.Minute
+}
+
+func (c *Config) GetMeetingEndingDuration() time.Duration {
+	return time.Duration(c.MeetingEndingTime) * time.Minute
+}
+
+// NewConfig returns the default settings: the built-in ones, replaced by the
+// system-wide config and the environment where those set them
 func NewConfig() *Config {
+	c := defaultConfig()
+	c.applySystemDefaults()
+	c.applyReadOnly()
+	return c
+}
+
+// defaultConfig returns the built-in default settings
+func defaultConfig() *Config {
 	return &Config{
 		Accounts:                []Account{},
 		EnabledCalendars:        []string{},
@@ -207,16 +564,33 @@ func NewConfig() *Config {
 		ShowMeetingLinks:        DefaultShowMeetingLinks,
 		PersistentNotifications: DefaultPersistentNotifications,
 		NotificationSound:       DefaultNotificationSound,
+		SoundFiles:              map[string]string{},
+		NotifyRescheduled:       DefaultNotifyRescheduled,
+		NotifyCancelled:         DefaultNotifyCancelled,
+		NotifyLinkChanged:       DefaultNotifyLinkChanged,
+		NotifyNewMeetings:       DefaultNotifyNewMeetings,
+		NotifyMeetingEnding:     DefaultNotifyMeetingEnding,
+		MeetingEndingTime:       DefaultMeetingEndingTime,
+		NotifyOverlap:           DefaultNotifyOverlap,
+		InterruptiveReminders:   DefaultInterruptiveReminders,
+		QuietHours:              []QuietHours{},
+		QuietMode:               DefaultQuietMode,
+		RespectDoNotDisturb:     DefaultRespectDoNotDisturb,
+		AlwaysAlert:             []AlertRule{},
 		ShowDuration:            DefaultShowDuration,
 		MaxMeetings:             DefaultMaxMeetings,
 		MaxTitleLength:          DefaultMaxTitleLength,
 		CurrentMeetingFormat:    DefaultCurrentMeetingFormat,
 		UpcomingMeetingFormat:   DefaultUpcomingMeetingFormat,
-		StartWithSystem:         DefaultStartWithSystem,
+		Profiles:                []Profile{},
+		ProfileSchedule:         []ProfileRule{},
 		AutoRefreshStartup:      DefaultAutoRefreshStartup,
 		LaunchAtLogin:           DefaultLaunchAtLogin,
+		AutostartMethod:         DefaultAutostartMethod,
 		Debug:                   false,
 		CalendarBackend:         DefaultCalendarBackend,
+		SecretStore:             DefaultSecretStore,
 		OAuth2:                  OAuth2Config{},
+		OAuthClients:            []OAuthClient{},
 	}
 }
\ No newline at end of file
@@ -2,17 +2,22 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
-	"github.com/zalando/go-keyring"
 	"golang.org/x/oauth2"
 )
 
 const (
+	// ServiceName is the keyring service of the usual instance; isolated
+	// instances use their InstanceName instead
 	ServiceName = "meetingbar"
 	TokenPrefix = "oauth_token_"
 )
 
+// ErrTokenNotFound is returned by GetToken when the secret store has no token for the account
+var ErrTokenNotFound = errors.New("no token stored for account")
+
 func StoreToken(accountID string, token *oauth2.Token) error {
 	tokenJSON, err := json.Marshal(token)
 	if err != nil {
@@ -20,14 +25,17 @@ func StoreToken(accountID string, token *oauth2.Token) error {
 	}
 	
 	key := TokenPrefix + accountID
-	return keyring.Set(ServiceName, key, string(tokenJSON))
+	return SetSecret(key, string(tokenJSON))
 }
 
 func GetToken(accountID string) (*oauth2.Token, error) {
 	key := TokenPrefix + accountID
-	tokenJSON, err := keyring.Get(ServiceName, key)
+	tokenJSON, err := GetSecret(key)
+	if errors.Is(err, ErrSecretNotFound) {
+		return nil, ErrTokenNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token from keyring: %w", err)
+		return nil, fmt.Errorf("failed to get token from secret store: %w", err)
 	}
 	
 	var token oauth2.Token
@@ -38,12 +46,13 @@ func GetToken(accountID string) (*oauth2.Token, error) {
 	return &token, nil
 }
 
+// DeleteToken removes the account's token; a token that is already gone is not an error
 func DeleteToken(accountID string) error {
 	key := TokenPrefix + accountID
-	return keyring.Delete(ServiceName, key)
+	return DeleteSecret(key)
 }
 
 // RemoveToken is an alias for DeleteToken for consistency
 func RemoveToken(accountID string) error {
 	return DeleteToken(accountID)
-}
\ No newline at end of file
+}
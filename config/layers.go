@@ -0,0 +1,414 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EnvPrefix starts the names of the environment variables that override
+// settings, e.g. MEETINGBAR_REFRESH_INTERVAL or MEETINGBAR_OAUTH2_CLIENT_ID
+const EnvPrefix = "MEETINGBAR"
+
+// lockedKey lists, in a system config file, the settings users cannot change
+const lockedKey = "locked"
+
+// Reasons shown next to settings that cannot be changed in the settings windows
+const (
+	reasonLocked = "locked by your administrator"
+	reasonEnv    = "set by %s"
+)
+
+// layers holds what Load found besides the user's config.json: the system-wide
+// defaults, the settings locked by them, and the settings taken from the environment
+type layers struct {
+	system        map[string]interface{} // by top-level key, as plain JSON values
+	systemSecrets map[string]string      // client secrets by secret store key
+	locked        map[string]bool
+	env           map[string]string      // top-level key to the variable setting it
+	userFile      map[string]interface{} // config.json as last read or written
+
+	// enforced holds the values of the read-only settings as loaded
+	enforced map[string]interface{}
+}
+
+var (
+	layersMu      sync.RWMutex
+	currentLayers layers
+)
+
+// systemConfigFiles returns the system-wide config files in XDG_CONFIG_DIRS,
+// least important first. The spec says relative paths must be ignored.
+func systemConfigFiles() []string {
+	dirs := os.Getenv("XDG_CONFIG_DIRS")
+	if dirs == "" {
+		dirs = "/etc/xdg"
+	}
+	list := filepath.SplitList(dirs)
+
+	var files []string
+	for i := len(list) - 1; i >= 0; i-- {
+		if filepath.IsAbs(list[i]) {
+			files = append(files, filepath.Join(list[i], appDirName, configFileName))
+		}
+	}
+	return files
+}
+
+// loadSystemLayer reads and merges the system-wide config files. A file that
+// cannot be used is skipped with a warning, so it cannot stop MeetingBar from
+// starting.
+func loadSystemLayer() (settings map[string]interface{}, secrets map[string]string, locked map[string]bool) {
+	settings = make(map[string]interface{})
+	secrets = make(map[string]string)
+	locked = make(map[string]bool)
+
+	for _, path := range systemConfigFiles() {
+		fileSettings, fileLocked, err := readSystemConfigFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Warning: ignoring system config file %s: %v", path, err)
+			continue
+		}
+
+		for key, value := range fileSettings {
+			settings[key] = value
+		}
+		for _, key := range fileLocked {
+			locked[key] = true
+		}
+	}
+
+	takeSystemSecrets(settings, secrets)
+	return settings, secrets, locked
+}
+
+// readSystemConfigFile returns the settings of a system config file, upgraded
+// to CurrentSchemaVersion, and the keys it locks
+func readSystemConfigFile(path string) (map[string]interface{}, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	version, err := schemaVersion(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version < CurrentSchemaVersion {
+		if err := MigrateSettings(settings, version); err != nil {
+			return nil, nil, err
+		}
+	}
+	delete(settings, "schema_version")
+
+	var locked []string
+	if value, ok := settings[lockedKey]; ok {
+		keys, ok := value.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("%q must be a list of setting names", lockedKey)
+		}
+		for _, key := range keys {
+			name, ok := key.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("%q must be a list of setting names", lockedKey)
+			}
+			locked = append(locked, name)
+		}
+		delete(settings, lockedKey)
+	}
+
+	// A file with values of the wrong type would make every Load fail
+	if err := decodeSettings(copySettings(settings), &Config{}); err != nil {
+		return nil, nil, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	normalized := normalizeValue(settings).(map[string]interface{})
+	return normalized, locked, nil
+}
+
+// takeSystemSecrets moves the client secrets out of the system settings, so
+// they are used without being copied into the user's secret store
+func takeSystemSecrets(settings map[string]interface{}, secrets map[string]string) {
+	if oauth2, ok := settings["oauth2"].(map[string]interface{}); ok {
+		if secret, ok := oauth2["client_secret"].(string); ok && secret != "" {
+			secrets[ClientSecretKey] = secret
+		}
+		delete(oauth2, "client_secret")
+	}
+
+	clients, _ := settings["oauth_clients"].([]interface{})
+	for _, entry := range clients {
+		client, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := client["name"].(string)
+		if secret, ok := client["client_secret"].(string); ok && secret != "" && name != "" {
+			secrets[OAuthClient{Name: name}.secretKey()] = secret
+		}
+		delete(client, "client_secret")
+	}
+}
+
+// envVariable returns the environment variable that overrides the setting
+// key, e.g. MEETINGBAR_OAUTH2_CLIENT_ID for "oauth2.client_id"
+func envVariable(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// envSettings returns the values of the environment variables that override
+// settings among keys, as listed by viper. A value that does not fit its
+// setting is ignored with a warning, so it cannot stop MeetingBar from starting.
+func envSettings(keys []string) map[string]string {
+	values := make(map[string]string)
+	for _, key := range keys {
+		variable := envVariable(key)
+		value := os.Getenv(variable)
+		if value == "" {
+			continue
+		}
+		if err := decodeSettings(nestedSetting(key, value), &Config{}); err != nil {
+			log.Printf("Warning: ignoring %s: %v", variable, err)
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// envSources maps the top-level settings overridden by environment variables
+// to the variable setting each
+func envSources(values map[string]string) map[string]string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sources := make(map[string]string)
+	for _, key := range keys {
+		topLevel := strings.SplitN(key, ".", 2)[0]
+		if _, ok := sources[topLevel]; !ok {
+			sources[topLevel] = envVariable(key)
+		}
+	}
+	return sources
+}
+
+// nestedSetting returns value keyed as in config.json, e.g.
+// {"oauth2": {"client_id": value}} for "oauth2.client_id"
+func nestedSetting(key string, value interface{}) map[string]interface{} {
+	parts := strings.Split(key, ".")
+	setting := map[string]interface{}{parts[len(parts)-1]: value}
+	for i := len(parts) - 2; i >= 0; i-- {
+		setting = map[string]interface{}{parts[i]: setting}
+	}
+	return setting
+}
+
+// readUserFile returns the settings in config.json as plain JSON values, or
+// none if there is no such file
+func readUserFile(path string) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if settings == nil {
+		settings = make(map[string]interface{})
+	}
+	return normalizeValue(settings).(map[string]interface{}), nil
+}
+
+// ReadOnlySettings returns the settings that cannot be changed in the settings
+// windows, by top-level key, with the reason to show next to each
+func ReadOnlySettings() map[string]string {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+
+	readOnly := make(map[string]string, len(currentLayers.locked)+len(currentLayers.env))
+	for key, variable := range currentLayers.env {
+		readOnly[key] = fmt.Sprintf(reasonEnv, variable)
+	}
+	for key := range currentLayers.locked {
+		readOnly[key] = reasonLocked
+	}
+	return readOnly
+}
+
+// IsReadOnly reports whether the setting key cannot be changed in the settings windows
+func IsReadOnly(key string) bool {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+	_, env := currentLayers.env[key]
+	return env || currentLayers.locked[key]
+}
+
+// applySystemDefaults replaces the built-in defaults with the system-wide ones
+func (c *Config) applySystemDefaults() {
+	layersMu.RLock()
+	system := currentLayers.system
+	layersMu.RUnlock()
+
+	if len(system) == 0 {
+		return
+	}
+	// The files were checked when they were read
+	if err := decodeSettings(copySettings(system), c); err != nil {
+		log.Printf("Warning: failed to apply system config: %v", err)
+	}
+	c.fillSystemSecrets()
+}
+
+// fillSystemSecrets gives the OAuth clients without a secret of their own the
+// one from the system config
+func (c *Config) fillSystemSecrets() {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+
+	if c.OAuth2.ClientSecret == "" {
+		c.OAuth2.ClientSecret = currentLayers.systemSecrets[ClientSecretKey]
+	}
+	for i := range c.OAuthClients {
+		client := &c.OAuthClients[i]
+		if client.ClientSecret == "" {
+			client.ClientSecret = currentLayers.systemSecrets[client.secretKey()]
+		}
+	}
+}
+
+// systemSecret returns the client secret the system config gives for key
+func systemSecret(key string) string {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+	return currentLayers.systemSecrets[key]
+}
+
+// enforceLocked sets the locked settings to their system-wide values, or the
+// built-in ones if the system config has none, whatever config.json says
+func (c *Config) enforceLocked() {
+	defaults, err := normalizeSettings(defaultConfig().settings())
+	if err != nil {
+		log.Printf("Warning: failed to apply locked settings: %v", err)
+		return
+	}
+
+	layersMu.RLock()
+	locked := make(map[string]interface{})
+	for key := range currentLayers.locked {
+		if value, ok := currentLayers.system[key]; ok {
+			locked[key] = value
+		} else if value, ok := defaults[key]; ok {
+			locked[key] = value
+		}
+	}
+	layersMu.RUnlock()
+
+	if err := decodeSettings(copySettings(locked), c); err != nil {
+		log.Printf("Warning: failed to apply locked settings: %v", err)
+	}
+}
+
+// rememberEnforced records the values of the read-only settings in c, so
+// they can be restored after changes made anyway
+func (c *Config) rememberEnforced() {
+	settings, err := normalizeSettings(c.settings())
+	if err != nil {
+		log.Printf("Warning: failed to record read-only settings: %v", err)
+		return
+	}
+
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	currentLayers.enforced = make(map[string]interface{})
+	for key := range settings {
+		if _, env := currentLayers.env[key]; env || currentLayers.locked[key] {
+			currentLayers.enforced[key] = settings[key]
+		}
+	}
+}
+
+// applyReadOnly sets the read-only settings back to their loaded values
+func (c *Config) applyReadOnly() {
+	layersMu.RLock()
+	enforced := copySettings(currentLayers.enforced)
+	layersMu.RUnlock()
+
+	if len(enforced) == 0 {
+		return
+	}
+	previous := c.Clone()
+	if err := decodeSettings(enforced, c); err != nil {
+		log.Printf("Warning: failed to apply read-only settings: %v", err)
+	}
+	c.restoreClientSecrets(previous, nil)
+	c.fillSystemSecrets()
+}
+
+// userSettings returns the settings to write to config.json: those that are
+// the user's own. Locked settings are left out, settings from the environment
+// keep their value in the file, and settings that equal the system-wide value
+// are only written when the file already had them.
+func (c *Config) userSettings() (map[string]interface{}, error) {
+	settings, err := normalizeSettings(c.settings())
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := normalizeSettings(NewConfig().settings())
+	if err != nil {
+		return nil, err
+	}
+
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+	for key, value := range settings {
+		fileValue, inFile := currentLayers.userFile[key]
+		_, inSystem := currentLayers.system[key]
+		_, fromEnv := currentLayers.env[key]
+
+		switch {
+		case currentLayers.locked[key]:
+			delete(settings, key)
+		case fromEnv && inFile:
+			settings[key] = fileValue
+		case fromEnv:
+			delete(settings, key)
+		case inSystem && !inFile && reflect.DeepEqual(value, defaults[key]):
+			delete(settings, key)
+		}
+	}
+	return settings, nil
+}
+
+// copySettings returns a copy of settings deep enough for decoding, which may
+// change the maps it is given
+func copySettings(settings map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return make(map[string]interface{})
+	}
+	var clone map[string]interface{}
+	if err := json.Unmarshal(data, &clone); err != nil || clone == nil {
+		return make(map[string]interface{})
+	}
+	return clone
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// CurrentSchemaVersion is the schema_version of config files written by this version
+const CurrentSchemaVersion = 3
+
+// migration upgrades the settings of a config file by one schema version
+type migration func(settings map[string]interface{}) error
+
+// migrations are the upgrades from each schema version to the next, in order:
+// migrations[0] upgrades version 0 (files without schema_version) to version 1.
+// Append new steps here and bump CurrentSchemaVersion whenever a key is renamed
+// or its meaning changes.
+var migrations = []migration{
+	migrateAccountKeys,
+	migrateOAuth2Keys,
+	migrateStartWithSystem,
+}
+
+// migrateConfigFile upgrades the config file at path to CurrentSchemaVersion.
+// The file as it was is kept next to it as config.json.v<version>.bak.
+func migrateConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	version, err := schemaVersion(settings)
+	if err != nil {
+		return err
+	}
+	if version > CurrentSchemaVersion {
+		log.Printf("Config file has schema version %d, but this version of MeetingBar only knows up to %d; unknown settings are ignored", version, CurrentSchemaVersion)
+		return nil
+	}
+	if version == CurrentSchemaVersion {
+		return nil
+	}
+
+	if err := MigrateSettings(settings, version); err != nil {
+		return err
+	}
+
+	// An existing backup is older still, so it is kept
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+		if err := os.WriteFile(backupPath, data, 0600); err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	migrated, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+
+	if err := writeFileAtomic(path, migrated, 0644); err != nil {
+		return fmt.Errorf("failed to write migrated config: %w", err)
+	}
+	rememberWrite(migrated)
+
+	log.Printf("Migrated config file from schema version %d to %d, the old file was saved as %s", version, CurrentSchemaVersion, backupPath)
+	return nil
+}
+
+// MigrateSettings upgrades decoded config file settings from schema version
+// from to CurrentSchemaVersion in place
+func MigrateSettings(settings map[string]interface{}, from int) error {
+	if from < 0 || from > CurrentSchemaVersion {
+		return fmt.Errorf("unknown config schema version %d", from)
+	}
+
+	for version := from; version < CurrentSchemaVersion; version++ {
+		if err := migrations[version](settings); err != nil {
+			return fmt.Errorf("failed to migrate config from schema version %d: %w", version, err)
+		}
+	}
+	settings["schema_version"] = CurrentSchemaVersion
+	return nil
+}
+
+// schemaVersion returns the schema_version of decoded settings, 0 if there is none
+func schemaVersion(settings map[string]interface{}) (int, error) {
+	value, ok := settings["schema_version"]
+	if !ok {
+		return 0, nil
+	}
+
+	version, ok := value.(float64)
+	if !ok || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid schema_version %v in config file", value)
+	}
+	return int(version), nil
+}
+
+// renameKeys renames the keys of m that match a key of renames case-insensitively.
+// A key that is already spelled as its new name is left alone.
+func renameKeys(m map[string]interface{}, renames map[string]string) {
+	for key, value := range m {
+		newKey, ok := renames[strings.ToLower(key)]
+		if !ok || key == newKey {
+			continue
+		}
+		delete(m, key)
+		if _, exists := m[newKey]; !exists {
+			m[newKey] = value
+		}
+	}
+}
+
+// migrateAccountKeys (version 0 to 1) renames the account keys that older
+// versions wrote as Go field names ("ID", "Email", "AddedAt"). "AddedAt" never
+// matched added_at, so the date an account was added was lost on every load.
+func migrateAccountKeys(settings map[string]interface{}) error {
+	accounts, ok := settings["accounts"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, entry := range accounts {
+		account, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid account entry %v", entry)
+		}
+		renameKeys(account, map[string]string{
+			"id":      "id",
+			"email":   "email",
+			"addedat": "added_at",
+		})
+	}
+	return nil
+}
+
+// migrateOAuth2Keys (version 1 to 2) renames oauth2.ClientID and
+// oauth2.ClientSecret to client_id and client_secret, as used by oauth_clients
+func migrateOAuth2Keys(settings map[string]interface{}) error {
+	oauth2, ok := settings["oauth2"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	renameKeys(oauth2, map[string]string{
+		"clientid":     "client_id",
+		"clientsecret": "client_secret",
+	})
+	return nil
+}
+
+// migrateStartWithSystem (version 2 to 3) merges start_with_system into
+// launch_at_login. Both meant starting MeetingBar at login, so either one
+// being set keeps it starting.
+func migrateStartWithSystem(settings map[string]interface{}) error {
+	for key, value := range settings {
+		if !strings.EqualFold(key, "start_with_system") {
+			continue
+		}
+		delete(settings, key)
+		if enabled, ok := value.(bool); ok && enabled {
+			settings["launch_at_login"] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the expected migration results in testdata")
+
+// TestMigrateConfigFile upgrades config files as older versions wrote them
+// and compares the result with testdata/migrate/v<N>.golden.json
+func TestMigrateConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+	}{
+		{name: "account keys as Go field names", version: 0},
+		{name: "oauth2 ClientID and ClientSecret", version: 1},
+		{name: "start_with_system", version: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", "migrate", versionFile(tt.version, ".json")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, input, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := migrateConfigFile(path); err != nil {
+				t.Fatalf("migrateConfigFile: %v", err)
+			}
+
+			migrated, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			goldenPath := filepath.Join("testdata", "migrate", versionFile(tt.version, ".golden.json"))
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, migrated, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			golden, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(migrated, golden) {
+				t.Errorf("migrated config differs from %s:\n%s\nwant:\n%s", goldenPath, migrated, golden)
+			}
+
+			// The file as it was is kept next to it
+			backup, err := os.ReadFile(fmt.Sprintf("%s.v%d.bak", path, tt.version))
+			if err != nil {
+				t.Fatalf("no backup of the old config: %v", err)
+			}
+			if !bytes.Equal(backup, input) {
+				t.Errorf("backup differs from the old config:\n%s\nwant:\n%s", backup, input)
+			}
+
+			assertMigrationIsNoop(t, path)
+		})
+	}
+}
+
+// TestMigrateCurrentConfigFile checks that a file of the current version is left alone
+func TestMigrateCurrentConfigFile(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "migrate", versionFile(0, ".golden.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, golden, 0600); err != nil {
+		t.Fatal(err)
+	}
+	assertMigrationIsNoop(t, path)
+}
+
+// assertMigrationIsNoop runs migrateConfigFile on an up-to-date file and
+// fails if it changes the file or writes anything next to it
+func assertMigrationIsNoop(t *testing.T, path string) {
+	t.Helper()
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entriesBefore, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateConfigFile(path); err != nil {
+		t.Fatalf("migrateConfigFile on a current file: %v", err)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("migrating a current file changed it:\n%s\nwas:\n%s", after, before)
+	}
+	entriesAfter, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entriesAfter) != len(entriesBefore) {
+		t.Errorf("migrating a current file left %d files, want %d", len(entriesAfter), len(entriesBefore))
+	}
+}
+
+func versionFile(version int, suffix string) string {
+	return fmt.Sprintf("v%d%s", version, suffix)
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ConfigDirEnv names the environment variable that moves the config directory,
+// e.g. to run an isolated instance for testing
+const ConfigDirEnv = "MEETINGBAR_CONFIG_DIR"
+
+const appDirName = "meetingbar"
+
+const (
+	logFileName = "meetingbar.log"
+
+	// maxLogSize is the size above which the log is rotated at startup
+	maxLogSize = 1 << 20
+)
+
+var (
+	configDirMu       sync.RWMutex
+	configDirOverride string
+)
+
+// SetConfigDir makes dir the config directory, taking precedence over
+// MEETINGBAR_CONFIG_DIR and the XDG directories. It is meant for the --config
+// flag and must be called before the config is loaded.
+func SetConfigDir(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+	configDirMu.Lock()
+	defer configDirMu.Unlock()
+	configDirOverride = absDir
+	return nil
+}
+
+// CustomConfigDir returns the config directory chosen with --config or
+// MEETINGBAR_CONFIG_DIR, or "" when none was chosen
+func CustomConfigDir() (string, error) {
+	configDirMu.RLock()
+	override := configDirOverride
+	configDirMu.RUnlock()
+	if override != "" {
+		return override, nil
+	}
+
+	dir := os.Getenv(ConfigDirEnv)
+	if dir == "" {
+		return "", nil
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ConfigDirEnv, err)
+	}
+	return absDir, nil
+}
+
+// ConfigDirID returns a short hash of the config directory chosen with --config
+// or MEETINGBAR_CONFIG_DIR, or "" when none was chosen. It tells isolated
+// instances apart where they share a namespace with the others.
+func ConfigDirID() (string, error) {
+	customDir, err := CustomConfigDir()
+	if err != nil || customDir == "" {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(customDir))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// InstanceName names what an instance keeps outside its own directories: its
+// keyring entries, autostart entry and systemd unit. It is "meetingbar", or
+// "meetingbar-" and the ConfigDirID for an isolated instance.
+func InstanceName() (string, error) {
+	id, err := ConfigDirID()
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return appDirName, nil
+	}
+	return appDirName + "-" + id, nil
+}
+
+// xdgBase returns the XDG base directory named by env, or fallback under the
+// home directory when env is unset. The spec says relative paths in these
+// variables must be ignored.
+func xdgBase(env string, fallback ...string) (string, error) {
+	if base := os.Getenv(env); base != "" && filepath.IsAbs(base) {
+		return base, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{homeDir}, fallback...)...), nil
+}
+
+// xdgDir returns the meetingbar directory inside the XDG base directory named by env
+func xdgDir(env string, fallback ...string) (string, error) {
+	base, err := xdgBase(env, fallback...)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, appDirName), nil
+}
+
+// appDir returns the directory for one kind of file. With a custom config
+// directory, the other kinds live in subdir of it; what lives elsewhere is
+// named after InstanceName, so isolated instances share nothing.
+func appDir(subdir, env string, fallback ...string) (string, error) {
+	customDir, err := CustomConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if customDir != "" {
+		return filepath.Join(customDir, subdir), nil
+	}
+	return xdgDir(env, fallback...)
+}
+
+func getConfigDir() (string, error) {
+	customDir, err := CustomConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if customDir != "" {
+		return customDir, nil
+	}
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// ConfigFile returns the path of config.json
+func ConfigFile() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, configFileName), nil
+}
+
+// AutostartFile returns the path of the XDG autostart entry that starts
+// MeetingBar at login. It belongs to the desktop, so a custom config directory
+// does not move it, but an isolated instance has an entry of its own.
+func AutostartFile() (string, error) {
+	base, err := xdgBase("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	name, err := InstanceName()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "autostart", name+".desktop"), nil
+}
+
+// SystemdUnitDir returns the directory for the user's own systemd units
+func SystemdUnitDir() (string, error) {
+	base, err := xdgBase("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "systemd", "user"), nil
+}
+
+func GetCacheDir() (string, error) {
+	return appDir("cache", "XDG_CACHE_HOME", ".cache")
+}
+
+// GetStateDir returns the directory for data that should survive restarts but
+// is not worth backing up, such as logs and the reminder history
+func GetStateDir() (string, error) {
+	return appDir("state", "XDG_STATE_HOME", ".local", "state")
+}
+
+func ensureConfigDir() error {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(configDir, 0755)
+}
+
+func EnsureCacheDir() error {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(cacheDir, 0755)
+}
+
+func EnsureStateDir() error {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(stateDir, 0700)
+}
+
+// StateFile returns the path of the named file in the state directory. A file
+// of the same name that older versions kept in the cache directory is moved there.
+func StateFile(name string) (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get state directory: %w", err)
+	}
+	path := filepath.Join(stateDir, name)
+
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return path, nil
+	}
+	oldPath := filepath.Join(cacheDir, name)
+	if _, err := os.Stat(oldPath); err != nil {
+		return path, nil
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return path, nil
+	}
+	if err := EnsureStateDir(); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.Rename(oldPath, path); err != nil {
+		return "", fmt.Errorf("failed to move %s to the state directory: %w", name, err)
+	}
+	return path, nil
+}
+
+// OpenLogFile opens the log file in the state directory for appending. A log
+// that has grown too large is kept as meetingbar.log.1 and a new one started.
+func OpenLogFile() (*os.File, error) {
+	if err := EnsureStateDir(); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state directory: %w", err)
+	}
+	path := filepath.Join(stateDir, logFileName)
+
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return nil, fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return file, nil
+}
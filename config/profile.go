@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// AutomaticProfile is the name used on the command line for switching
+// profiles by profile_schedule instead of using a chosen one
+const AutomaticProfile = "auto"
+
+// Profile is a named set of settings, e.g. "vacation" or "presenting", that
+// replaces the matching general settings while it is in effect. Settings left
+// out of the profile keep their general value.
+type Profile struct {
+	Name string `mapstructure:"name" json:"name"`
+
+	EnabledCalendars        *[]string `mapstructure:"enabled_calendars" json:"enabled_calendars,omitempty"`
+	EnableNotifications     *bool     `mapstructure:"enable_notifications" json:"enable_notifications,omitempty"`
+	NotificationTime        *int      `mapstructure:"notification_time" json:"notification_time,omitempty"`
+	PersistentNotifications *bool     `mapstructure:"persistent_notifications" json:"persistent_notifications,omitempty"`
+	NotificationSound       *bool     `mapstructure:"notification_sound" json:"notification_sound,omitempty"`
+	InterruptiveReminders   *bool     `mapstructure:"interruptive_reminders" json:"interruptive_reminders,omitempty"`
+	NotifyMeetingEnding     *bool     `mapstructure:"notify_meeting_ending" json:"notify_meeting_ending,omitempty"`
+	ShowDuration            *bool     `mapstructure:"show_duration" json:"show_duration,omitempty"`
+	MaxTitleLength          *int      `mapstructure:"max_title_length" json:"max_title_length,omitempty"`
+	CurrentMeetingFormat    *string   `mapstructure:"current_meeting_format" json:"current_meeting_format,omitempty"`
+	UpcomingMeetingFormat   *string   `mapstructure:"upcoming_meeting_format" json:"upcoming_meeting_format,omitempty"`
+}
+
+// ProfileRule puts a profile in effect during a recurring period, e.g. on
+// weekends, unless a profile was chosen by hand
+type ProfileRule struct {
+	Profile string   `mapstructure:"profile" json:"profile"`
+	Days    []string `mapstructure:"days" json:"days"`   // "mon" to "sun"; empty means every day
+	Start   string   `mapstructure:"start" json:"start"` // "15:04"
+	End     string   `mapstructure:"end" json:"end"`     // before Start for periods spanning midnight
+}
+
+// Contains reports whether now is inside the rule's period
+func (r ProfileRule) Contains(now time.Time) (bool, error) {
+	return periodContains(r.Days, r.Start, r.End, now)
+}
+
+// Profile returns the profile with the given name
+func (c *Config) Profile(name string) (Profile, bool) {
+	for _, profile := range c.Profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// SelectProfile keeps the named profile in effect until another one is
+// chosen. An empty name switches profiles by profile_schedule again.
+func (c *Config) SelectProfile(name string) error {
+	if name != "" {
+		if _, ok := c.Profile(name); !ok {
+			return fmt.Errorf("no profile named %q", name)
+		}
+	}
+	c.ActiveProfile = name
+	return nil
+}
+
+// CurrentProfile returns the name of the profile in effect at now: the chosen
+// one, or else the profile of the first schedule rule containing now. An
+// empty name means only the general settings apply.
+func (c *Config) CurrentProfile(now time.Time) string {
+	if c.ActiveProfile != "" {
+		return c.ActiveProfile
+	}
+	for _, rule := range c.ProfileSchedule {
+		if _, ok := c.Profile(rule.Profile); !ok {
+			continue
+		}
+		if active, err := rule.Contains(now); err == nil && active {
+			return rule.Profile
+		}
+	}
+	return ""
+}
+
+// Effective returns a copy of the settings with the profile in effect at now
+// applied, which is what the tray and the reminders follow. Profiles cannot
+// change read-only settings.
+func (c *Config) Effective(now time.Time) *Config {
+	effective := c.Clone()
+	if profile, ok := c.Profile(c.CurrentProfile(now)); ok {
+		profile.apply(effective)
+		effective.applyReadOnly()
+	}
+	return effective
+}
+
+func (p Profile) apply(c *Config) {
+	if p.EnabledCalendars != nil {
+		c.EnabledCalendars = cloneStrings(*p.EnabledCalendars)
+	}
+	if p.EnableNotifications != nil {
+		c.EnableNotifications = *p.EnableNotifications
+	}
+	if p.NotificationTime != nil {
+		c.NotificationTime = *p.NotificationTime
+	}
+	if p.PersistentNotifications != nil {
+		c.PersistentNotifications = *p.PersistentNotifications
+	}
+	if p.NotificationSound != nil {
+		c.NotificationSound = *p.NotificationSound
+	}
+	if p.InterruptiveReminders != nil {
+		c.InterruptiveReminders = *p.InterruptiveReminders
+	}
+	if p.NotifyMeetingEnding != nil {
+		c.NotifyMeetingEnding = *p.NotifyMeetingEnding
+	}
+	if p.ShowDuration != nil {
+		c.ShowDuration = *p.ShowDuration
+	}
+	if p.MaxTitleLength != nil {
+		c.MaxTitleLength = *p.MaxTitleLength
+	}
+	if p.CurrentMeetingFormat != nil {
+		c.CurrentMeetingFormat = *p.CurrentMeetingFormat
+	}
+	if p.UpcomingMeetingFormat != nil {
+		c.UpcomingMeetingFormat = *p.UpcomingMeetingFormat
+	}
+}
+
+// clone returns a copy of the profile that shares no settings with it
+func (p Profile) clone() Profile {
+	if p.EnabledCalendars != nil {
+		calendars := cloneStrings(*p.EnabledCalendars)
+		p.EnabledCalendars = &calendars
+	}
+	p.EnableNotifications = cloneBool(p.EnableNotifications)
+	p.NotificationTime = cloneInt(p.NotificationTime)
+	p.PersistentNotifications = cloneBool(p.PersistentNotifications)
+	p.NotificationSound = cloneBool(p.NotificationSound)
+	p.InterruptiveReminders = cloneBool(p.InterruptiveReminders)
+	p.NotifyMeetingEnding = cloneBool(p.NotifyMeetingEnding)
+	p.ShowDuration = cloneBool(p.ShowDuration)
+	p.MaxTitleLength = cloneInt(p.MaxTitleLength)
+	p.CurrentMeetingFormat = cloneString(p.CurrentMeetingFormat)
+	p.UpcomingMeetingFormat = cloneString(p.UpcomingMeetingFormat)
+	return p
+}
+
+// clearSetting removes the profile's value for key, so the general one applies
+func (p *Profile) clearSetting(key string) bool {
+	switch key {
+	case "notification_time":
+		p.NotificationTime = nil
+	case "max_title_length":
+		p.MaxTitleLength = nil
+	case "current_meeting_format":
+		p.CurrentMeetingFormat = nil
+	case "upcoming_meeting_format":
+		p.UpcomingMeetingFormat = nil
+	default:
+		return false
+	}
+	return true
+}
+
+var profileSettingPattern = regexp.MustCompile(`^profiles\[(\d+)\]\.(\w+)$`)
+
+// clearProfileSetting removes the profile setting named by a FieldError field,
+// e.g. "profiles[0].notification_time", so the general setting applies
+func (c *Config) clearProfileSetting(field string) bool {
+	match := profileSettingPattern.FindStringSubmatch(field)
+	if match == nil {
+		return false
+	}
+	i, err := strconv.Atoi(match[1])
+	if err != nil || i >= len(c.Profiles) {
+		return false
+	}
+	return c.Profiles[i].clearSetting(match[2])
+}
+
+func cloneBool(value *bool) *bool {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
+
+func cloneInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
+
+func cloneString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
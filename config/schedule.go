@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Contains reports whether now is inside the quiet hours period
+func (q QuietHours) Contains(now time.Time) (bool, error) {
+	return periodContains(q.Days, q.Start, q.End, now)
+}
+
+// periodContains reports whether now is inside the period from start to end on
+// the given days. A period whose end is before its start spans midnight and
+// belongs to the day it starts on; equal start and end times cover the whole day.
+func periodContains(days []string, startClock, endClock string, now time.Time) (bool, error) {
+	start, err := parseClock(startClock)
+	if err != nil {
+		return false, err
+	}
+	end, err := parseClock(endClock)
+	if err != nil {
+		return false, err
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	today := now.Weekday()
+
+	switch {
+	case start == end:
+		return matchesWeekday(days, today), nil
+	case start < end:
+		return minutes >= start && minutes < end && matchesWeekday(days, today), nil
+	case minutes >= start:
+		return matchesWeekday(days, today), nil
+	case minutes < end:
+		return matchesWeekday(days, (today+6)%7), nil
+	default:
+		return false, nil
+	}
+}
+
+// parseClock converts "15:04" into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// matchesWeekday checks abbreviated day names such as "mon"; no days means every day
+func matchesWeekday(days []string, weekday time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	name := strings.ToLower(weekday.String()[:3])
+	for _, day := range days {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(day)), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,485 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Secret store preferences; "auto" uses the keyring when a Secret Service is running
+const (
+	SecretStoreAuto    = "auto"
+	SecretStoreKeyring = "keyring"
+	SecretStoreFile    = "file"
+)
+
+const (
+	secretsFile = "secrets.enc"
+
+	// SecretPassphraseEnv holds the passphrase for the encrypted secrets file.
+	// Without it the file is encrypted with a key bound to this machine and user.
+	SecretPassphraseEnv = "MEETINGBAR_SECRET_PASSPHRASE"
+
+	keySourcePassphrase = "passphrase"
+	keySourceMachine    = "machine"
+
+	// secretsAdditionalData ties the ciphertext to this file format
+	secretsAdditionalData = "meetingbar-secrets-v1"
+)
+
+// ErrSecretNotFound is returned by SecretStore.Get for keys that are not stored
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore keeps small secrets such as OAuth tokens
+type SecretStore interface {
+	// Name is SecretStoreKeyring or SecretStoreFile
+	Name() string
+	// Description tells the user where secrets are kept
+	Description() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	// Delete removes the key; a missing key is not an error
+	Delete(key string) error
+}
+
+// keyringStore keeps secrets in the desktop keyring via the Secret Service API
+type keyringStore struct{}
+
+func (keyringStore) Name() string {
+	return SecretStoreKeyring
+}
+
+func (keyringStore) Description() string {
+	return "System keyring (Secret Service)"
+}
+
+// keyringService returns the keyring service the secrets of this instance are
+// kept under, so isolated instances do not share tokens with the others
+func keyringService() (string, error) {
+	name, err := InstanceName()
+	if err != nil {
+		return "", fmt.Errorf("failed to get keyring service: %w", err)
+	}
+	return name, nil
+}
+
+func (keyringStore) Get(key string) (string, error) {
+	service, err := keyringService()
+	if err != nil {
+		return "", err
+	}
+	value, err := keyring.Get(service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read from keyring: %w", err)
+	}
+	return value, nil
+}
+
+func (keyringStore) Set(key, value string) error {
+	service, err := keyringService()
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(service, key, value); err != nil {
+		return fmt.Errorf("failed to write to keyring: %w", err)
+	}
+	return nil
+}
+
+func (keyringStore) Delete(key string) error {
+	service, err := keyringService()
+	if err != nil {
+		return err
+	}
+	if err := keyring.Delete(service, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete from keyring: %w", err)
+	}
+	return nil
+}
+
+// keyringAvailable reports whether a Secret Service answers; window managers
+// without gnome-keyring or KWallet and most containers have none
+func keyringAvailable() bool {
+	_, err := keyring.Get(ServiceName, "availability_probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+// fileStore keeps secrets in an AES-GCM encrypted file in the config directory.
+// The key is derived with scrypt from the passphrase in SecretPassphraseEnv or,
+// without one, from the machine ID and user. A machine-bound key keeps the file
+// useless on other machines but does not protect it from other programs run by
+// the same user.
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+
+	// The derived key is cached, since scrypt is deliberately slow
+	keySource string
+	salt      []byte
+	aead      cipher.AEAD
+}
+
+// secretsFileContents is the on-disk format of the encrypted secrets file
+type secretsFileContents struct {
+	KeySource string `json:"key_source"`
+	Salt      []byte `json:"salt"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+func newFileStore() (*fileStore, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return &fileStore{path: filepath.Join(configDir, secretsFile)}, nil
+}
+
+func (s *fileStore) Name() string {
+	return SecretStoreFile
+}
+
+func (s *fileStore) Description() string {
+	if os.Getenv(SecretPassphraseEnv) != "" {
+		return "Encrypted file (passphrase)"
+	}
+	return "Encrypted file (machine-bound key)"
+}
+
+func (s *fileStore) exists() bool {
+	_, err := os.Stat(s.path)
+	return err == nil
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+// load decrypts the secrets file; a missing file holds no secrets
+func (s *fileStore) load() (map[string]string, error) {
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var contents secretsFileContents
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+
+	gcm, err := s.cipher(contents.KeySource, contents.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, contents.Nonce, contents.Data, []byte(secretsAdditionalData))
+	if err != nil {
+		if contents.KeySource == keySourcePassphrase {
+			return nil, fmt.Errorf("failed to decrypt secrets file, check %s", SecretPassphraseEnv)
+		}
+		return nil, fmt.Errorf("failed to decrypt secrets file, it was created on another machine or by another user")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts the secrets with a fresh nonce and replaces the file atomically.
+// A new salt is only generated when the key source changes.
+func (s *fileStore) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	contents := secretsFileContents{KeySource: keySourceMachine}
+	if os.Getenv(SecretPassphraseEnv) != "" {
+		contents.KeySource = keySourcePassphrase
+	}
+	if s.aead != nil && s.keySource == contents.KeySource {
+		contents.Salt = s.salt
+	} else {
+		contents.Salt = make([]byte, 16)
+		if _, err := rand.Read(contents.Salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+
+	gcm, err := s.cipher(contents.KeySource, contents.Salt)
+	if err != nil {
+		return err
+	}
+	contents.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(contents.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	contents.Data = gcm.Seal(nil, contents.Nonce, plaintext, []byte(secretsAdditionalData))
+
+	raw, err := json.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets file: %w", err)
+	}
+
+	if err := ensureConfigDir(); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, raw, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return nil
+}
+
+// cipher returns the cached cipher when the key source and salt match, or derives a new one
+func (s *fileStore) cipher(keySource string, salt []byte) (cipher.AEAD, error) {
+	if s.aead != nil && s.keySource == keySource && bytes.Equal(s.salt, salt) {
+		return s.aead, nil
+	}
+
+	aead, err := secretsCipher(keySource, salt)
+	if err != nil {
+		return nil, err
+	}
+	s.keySource, s.salt, s.aead = keySource, salt, aead
+	return aead, nil
+}
+
+// secretsCipher derives the file key for the given key source and salt
+func secretsCipher(keySource string, salt []byte) (cipher.AEAD, error) {
+	var material string
+	switch keySource {
+	case keySourcePassphrase:
+		material = os.Getenv(SecretPassphraseEnv)
+		if material == "" {
+			return nil, fmt.Errorf("the secrets file is protected by a passphrase, set %s", SecretPassphraseEnv)
+		}
+	case keySourceMachine:
+		var err error
+		if material, err = machineKeyMaterial(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown key source %q in secrets file", keySource)
+	}
+	return passphraseCipher(material, salt)
+}
+
+// passphraseCipher derives an AES-GCM cipher from a passphrase and salt
+func passphraseCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// machineKeyMaterial combines the machine ID with the current user
+func machineKeyMaterial() (string, error) {
+	var machineID []byte
+	var err error
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if machineID, err = os.ReadFile(path); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read machine ID, set %s instead: %w", SecretPassphraseEnv, err)
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+	return strings.TrimSpace(string(machineID)) + ":" + current.Uid + ":" + current.Username, nil
+}
+
+var (
+	secretStoreMu         sync.Mutex
+	secretStorePreference string
+	activeSecretStore     SecretStore
+	fallbackSecretStore   SecretStore
+)
+
+// UseSecretStore selects the secret store for the preference from the config.
+// Load calls it, so it only probes the keyring again when the preference changes.
+func UseSecretStore(preference string) {
+	secretStoreMu.Lock()
+	defer secretStoreMu.Unlock()
+
+	if activeSecretStore != nil && preference == secretStorePreference {
+		return
+	}
+	secretStorePreference = preference
+
+	files, err := newFileStore()
+	if err != nil {
+		log.Printf("Encrypted secrets file unavailable: %v", err)
+	}
+
+	useKeyring := preference == SecretStoreKeyring
+	if preference != SecretStoreKeyring && preference != SecretStoreFile {
+		useKeyring = keyringAvailable() || files == nil
+	}
+
+	activeSecretStore, fallbackSecretStore = keyringStore{}, nil
+	if !useKeyring {
+		activeSecretStore = files
+	}
+
+	// The other store is checked for secrets that have not been moved yet
+	if useKeyring && files != nil && files.exists() {
+		fallbackSecretStore = files
+	} else if !useKeyring && keyringAvailable() {
+		fallbackSecretStore = keyringStore{}
+	}
+
+	log.Printf("Storing secrets in: %s", activeSecretStore.Description())
+}
+
+// ActiveSecretStore returns the store secrets are written to
+func ActiveSecretStore() SecretStore {
+	secretStoreMu.Lock()
+	selected := activeSecretStore != nil
+	secretStoreMu.Unlock()
+
+	if !selected {
+		UseSecretStore(SecretStoreAuto)
+	}
+
+	secretStoreMu.Lock()
+	defer secretStoreMu.Unlock()
+	return activeSecretStore
+}
+
+func currentFallbackSecretStore() SecretStore {
+	secretStoreMu.Lock()
+	defer secretStoreMu.Unlock()
+	return fallbackSecretStore
+}
+
+// GetSecret reads a secret from the active store. Secrets still kept in the
+// other store, e.g. after the keyring became unavailable, are moved over.
+func GetSecret(key string) (string, error) {
+	store := ActiveSecretStore()
+	value, err := store.Get(key)
+	if !errors.Is(err, ErrSecretNotFound) {
+		return value, err
+	}
+
+	fallback := currentFallbackSecretStore()
+	if fallback == nil {
+		return "", ErrSecretNotFound
+	}
+	value, err = fallback.Get(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := store.Set(key, value); err != nil {
+		log.Printf("Failed to move secret to %s: %v", store.Description(), err)
+		return value, nil
+	}
+	if err := fallback.Delete(key); err != nil {
+		log.Printf("Failed to remove moved secret from %s: %v", fallback.Description(), err)
+	}
+	log.Printf("Moved secret %s from %s to %s", key, fallback.Description(), store.Description())
+	return value, nil
+}
+
+// SetSecret writes a secret to the active store
+func SetSecret(key, value string) error {
+	return ActiveSecretStore().Set(key, value)
+}
+
+// DeleteSecret removes a secret from both stores
+func DeleteSecret(key string) error {
+	if fallback := currentFallbackSecretStore(); fallback != nil {
+		if err := fallback.Delete(key); err != nil {
+			log.Printf("Failed to delete secret from %s: %v", fallback.Description(), err)
+		}
+	}
+	return ActiveSecretStore().Delete(key)
+}
+
+// MigrateSecrets moves the client secrets and the tokens of all accounts into the active store
+func MigrateSecrets(cfg *Config) {
+	if _, err := GetSecret(ClientSecretKey); err != nil && !errors.Is(err, ErrSecretNotFound) {
+		log.Printf("Failed to migrate OAuth2 client secret: %v", err)
+	}
+	for _, client := range cfg.OAuthClients {
+		if _, err := GetSecret(client.secretKey()); err != nil && !errors.Is(err, ErrSecretNotFound) {
+			log.Printf("Failed to migrate secret of OAuth client %s: %v", client.Name, err)
+		}
+	}
+	for _, account := range cfg.Accounts {
+		if _, err := GetSecret(TokenPrefix + account.ID); err != nil && !errors.Is(err, ErrSecretNotFound) {
+			log.Printf("Failed to migrate token for %s: %v", account.Email, err)
+		}
+	}
+}
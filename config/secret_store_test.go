@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+// TestIsolatedInstanceKeyring checks that an instance with a custom config
+// directory keeps its keyring entries apart from the usual instance's
+func TestIsolatedInstanceKeyring(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(ConfigDirEnv, "")
+	keyring.MockInit()
+	UseSecretStore(SecretStoreKeyring)
+
+	if err := StoreToken("user-1", &oauth2.Token{RefreshToken: "usual"}); err != nil {
+		t.Fatalf("StoreToken: %v", err)
+	}
+
+	t.Setenv(ConfigDirEnv, t.TempDir())
+	if _, err := GetToken("user-1"); err != ErrTokenNotFound {
+		t.Errorf("isolated instance GetToken: %v, want ErrTokenNotFound", err)
+	}
+	if err := StoreToken("user-1", &oauth2.Token{RefreshToken: "isolated"}); err != nil {
+		t.Fatalf("StoreToken in isolated instance: %v", err)
+	}
+
+	t.Setenv(ConfigDirEnv, "")
+	token, err := GetToken("user-1")
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if token.RefreshToken != "usual" {
+		t.Errorf("refresh token %q, want the usual instance's; the isolated instance overwrote it", token.RefreshToken)
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Store holds the settings shared by the tray, the settings windows, the
+// calendar services and the notifications. It is safe for concurrent use:
+// readers get snapshots that never change under them, and every change is
+// saved and then announced to the subscribers.
+type Store struct {
+	mu      sync.RWMutex
+	cfg     *Config
+	version int // counts the changes, so older ones are not announced after newer ones
+
+	notifyMu    sync.Mutex
+	notified    int
+	subMu       sync.Mutex
+	subscribers map[int]func(*Config)
+	nextID      int
+}
+
+// NewStore returns a store holding cfg. The store takes ownership of cfg, so
+// the caller must not use it afterwards.
+func NewStore(cfg *Config) *Store {
+	return &Store{
+		cfg:         cfg,
+		subscribers: make(map[int]func(*Config)),
+	}
+}
+
+// Get returns a snapshot of the current settings. Changing it does not change the store.
+func (s *Store) Get() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.Clone()
+}
+
+// Update applies update to a copy of the settings and, unless update returns
+// an error, saves the copy and makes it the current settings. Updates are
+// applied one at a time, so update always sees the latest settings.
+func (s *Store) Update(update func(*Config) error) error {
+	s.mu.Lock()
+	updated := s.cfg.Clone()
+	if err := update(updated); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	// Read-only settings stay as loaded, whatever update did to them
+	updated.applyReadOnly()
+	if err := updated.Save(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.cfg = updated
+	s.version++
+	version := s.version
+	s.mu.Unlock()
+
+	s.notify(version, updated)
+	return nil
+}
+
+// Set saves cfg as the new settings. It is meant for settings windows that
+// edit a snapshot and save it as a whole.
+func (s *Store) Set(cfg *Config) error {
+	return s.Update(func(c *Config) error {
+		*c = *cfg.Clone()
+		return nil
+	})
+}
+
+// reload makes the config file at path the current settings when another
+// program changed it. The file is read while updates are held off, so an
+// update cannot save settings it read before the reload over the file, and
+// the settings in memory are always those in the file.
+func (s *Store) reload(path string) {
+	s.mu.Lock()
+	cfg, changed := loadChangedConfig(path)
+	if !changed {
+		s.mu.Unlock()
+		return
+	}
+	s.cfg = cfg
+	s.version++
+	version := s.version
+	s.mu.Unlock()
+
+	log.Printf("Config file changed on disk, applying the new settings")
+	s.notify(version, cfg)
+}
+
+// Subscribe calls onChange with the new settings after a change, on the
+// goroutine that made it. When changes are made at the same time, onChange may
+// only see the latest one, but never an older one after a newer one. onChange
+// gets its own copy of the settings; it may read the store, but changing it
+// must be left to another goroutine. The returned function ends the subscription.
+func (s *Store) Subscribe(onChange func(*Config)) func() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = onChange
+
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+func (s *Store) notify(version int, cfg *Config) {
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	if version <= s.notified {
+		return
+	}
+	s.notified = version
+
+	s.subMu.Lock()
+	subscribers := make([]func(*Config), 0, len(s.subscribers))
+	for _, onChange := range s.subscribers {
+		subscribers = append(subscribers, onChange)
+	}
+	s.subMu.Unlock()
+
+	for _, onChange := range subscribers {
+		onChange(cfg.Clone())
+	}
+}
+
+// Effective returns a snapshot of the settings with the profile currently in
+// effect applied. Settings windows edit Get instead, so profiles stay separate.
+func (s *Store) Effective() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.Effective(time.Now())
+}
+
+// RefreshInterval returns how often calendars are refreshed
+func (s *Store) RefreshInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.GetRefreshDuration()
+}
+
+// CalendarBackend returns the calendar backend in use, "google" or "gnome"
+func (s *Store) CalendarBackend() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.CalendarBackend
+}
+
+// Accounts returns a copy of the configured Google accounts
+func (s *Store) Accounts() []Account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneAccounts(s.cfg.Accounts)
+}
+
+// Account returns the account with the given ID
+func (s *Store) Account(id string) (Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, account := range s.cfg.Accounts {
+		if account.ID == id {
+			return cloneAccounts([]Account{account})[0], true
+		}
+	}
+	return Account{}, false
+}
+
+// EnabledCalendars returns a copy of the IDs of the calendars to show, as
+// chosen by the profile in effect
+func (s *Store) EnabledCalendars() []string {
+	return s.Effective().EnabledCalendars
+}
+
+// Clone returns a deep copy of the config
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.Accounts = cloneAccounts(c.Accounts)
+	clone.EnabledCalendars = cloneStrings(c.EnabledCalendars)
+	if c.SoundFiles != nil {
+		clone.SoundFiles = make(map[string]string, len(c.SoundFiles))
+		for reminderType, file := range c.SoundFiles {
+			clone.SoundFiles[reminderType] = file
+		}
+	}
+	if c.QuietHours != nil {
+		clone.QuietHours = make([]QuietHours, len(c.QuietHours))
+		for i, period := range c.QuietHours {
+			period.Days = cloneStrings(period.Days)
+			period.Calendars = cloneStrings(period.Calendars)
+			clone.QuietHours[i] = period
+		}
+	}
+	if c.AlwaysAlert != nil {
+		clone.AlwaysAlert = append([]AlertRule{}, c.AlwaysAlert...)
+	}
+	if c.OAuthClients != nil {
+		clone.OAuthClients = append([]OAuthClient{}, c.OAuthClients...)
+	}
+	if c.Profiles != nil {
+		clone.Profiles = make([]Profile, len(c.Profiles))
+		for i, profile := range c.Profiles {
+			clone.Profiles[i] = profile.clone()
+		}
+	}
+	if c.ProfileSchedule != nil {
+		clone.ProfileSchedule = make([]ProfileRule, len(c.ProfileSchedule))
+		for i, rule := range c.ProfileSchedule {
+			rule.Days = cloneStrings(rule.Days)
+			clone.ProfileSchedule[i] = rule
+		}
+	}
+	return &clone
+}
+
+func cloneAccounts(accounts []Account) []Account {
+	if accounts == nil {
+		return nil
+	}
+	clone := make([]Account, len(accounts))
+	for i, account := range accounts {
+		account.MissingScopes = cloneStrings(account.MissingScopes)
+		clone[i] = account
+	}
+	return clone
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
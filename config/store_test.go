@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// loadTestConfig loads a config from a temporary home directory, with secrets
+// kept in an in-memory keyring
+func loadTestConfig(t *testing.T) *Config {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(home, "etc"))
+	t.Setenv(ConfigDirEnv, "")
+	keyring.MockInit()
+
+	dir := filepath.Join(home, ".config", appDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"schema_version": 3, "secret_store": "keyring", "refresh_interval": 5, "enabled_calendars": ["primary"]}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(settings), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+// TestStoreConcurrentUpdates runs updates alongside readers that change their
+// snapshots. Every update must see the one before it, and no reader may see
+// another's changes.
+func TestStoreConcurrentUpdates(t *testing.T) {
+	store := NewStore(loadTestConfig(t))
+
+	const writers, updatesPerWriter, readers = 4, 10, 4
+	done := make(chan struct{})
+	var wg, readersWG sync.WaitGroup
+
+	for i := 0; i < readers; i++ {
+		readersWG.Add(1)
+		go func(reader int) {
+			defer readersWG.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snapshot := store.Get()
+				snapshot.EnabledCalendars = append(snapshot.EnabledCalendars, fmt.Sprintf("reader-%d", reader))
+				snapshot.SoundFiles = map[string]string{"start": "reader.oga"}
+				store.Effective()
+				store.Accounts()
+				store.EnabledCalendars()
+				store.RefreshInterval()
+			}
+		}(i)
+	}
+
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < updatesPerWriter; j++ {
+				err := store.Update(func(cfg *Config) error {
+					cfg.RefreshInterval++
+					return nil
+				})
+				if err != nil {
+					t.Errorf("Update: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	readersWG.Wait()
+
+	cfg := store.Get()
+	if want := 5 + writers*updatesPerWriter; cfg.RefreshInterval != want {
+		t.Errorf("refresh interval %d after the updates, want %d", cfg.RefreshInterval, want)
+	}
+	if len(cfg.EnabledCalendars) != 1 || cfg.EnabledCalendars[0] != "primary" {
+		t.Errorf("enabled calendars %v, want [primary]; a snapshot changed the store", cfg.EnabledCalendars)
+	}
+	if len(cfg.SoundFiles) != 0 {
+		t.Errorf("sound files %v, want none; a snapshot changed the store", cfg.SoundFiles)
+	}
+
+	saved, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if saved.RefreshInterval != cfg.RefreshInterval {
+		t.Errorf("saved refresh interval %d, want %d", saved.RefreshInterval, cfg.RefreshInterval)
+	}
+}
+
+// TestStoreSubscribersSeeChangesInOrder checks that subscribers, which may read
+// the store themselves, never see an older change after a newer one
+func TestStoreSubscribersSeeChangesInOrder(t *testing.T) {
+	store := NewStore(loadTestConfig(t))
+
+	var mu sync.Mutex
+	var seen []int
+	unsubscribe := store.Subscribe(func(cfg *Config) {
+		store.Get()
+		mu.Lock()
+		seen = append(seen, cfg.RefreshInterval)
+		mu.Unlock()
+		cfg.RefreshInterval = -1
+	})
+	defer unsubscribe()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				store.Update(func(cfg *Config) error {
+					cfg.RefreshInterval++
+					return nil
+				})
+			}
+		}()
+	}
+
+	// Subscriptions come and go while changes are announced
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				store.Subscribe(func(*Config) {})()
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) == 0 {
+		t.Fatal("the subscriber saw no changes")
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i] <= seen[i-1] {
+			t.Fatalf("the subscriber saw %d after %d: %v", seen[i], seen[i-1], seen)
+		}
+	}
+	if last, current := seen[len(seen)-1], store.Get().RefreshInterval; last != current {
+		t.Errorf("the subscriber last saw %d, want the current %d", last, current)
+	}
+}
+
+// TestStoreConcurrentReload changes config.json as another program would and
+// reloads it, as the config watcher does, alongside updates and readers. The
+// settings in memory must end up as those in the file.
+func TestStoreConcurrentReload(t *testing.T) {
+	store := NewStore(loadTestConfig(t))
+	configDir, err := getConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(configDir, configFileName)
+
+	var mu sync.Mutex
+	count := 0
+	defer store.Subscribe(func(*Config) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				// Replaced as a whole, as editors and dotfile managers do
+				settings := fmt.Sprintf(`{"schema_version": 3, "secret_store": "keyring", "enabled_calendars": ["primary"], "max_meetings": %d}`, 1+i*10+j)
+				tmp := fmt.Sprintf("%s.%d", path, i)
+				if err := os.WriteFile(tmp, []byte(settings), 0600); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := os.Rename(tmp, path); err != nil {
+					t.Error(err)
+					return
+				}
+				store.reload(path)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				store.Update(func(cfg *Config) error {
+					cfg.ShowDuration = !cfg.ShowDuration
+					return nil
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				store.Get()
+				store.CalendarBackend()
+				store.Account("missing")
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	cfg := store.Get()
+	if cfg.MaxMeetings != saved.MaxMeetings || cfg.ShowDuration != saved.ShowDuration {
+		t.Errorf("settings in memory (max meetings %d, show duration %t) differ from the file (%d, %t)",
+			cfg.MaxMeetings, cfg.ShowDuration, saved.MaxMeetings, saved.ShowDuration)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if count == 0 {
+		t.Error("the subscriber saw no changes")
+	}
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FieldError describes an invalid setting. Field is the setting's key in
+// config.json, with an index for list entries, e.g. "quiet_hours[1].start".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors lists every invalid setting found by Validate
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return "invalid settings: " + strings.Join(messages, "; ")
+}
+
+// Fields maps each invalid field to its message, for showing them next to the inputs
+func (e ValidationErrors) Fields() map[string]string {
+	fields := make(map[string]string, len(e))
+	for _, fieldErr := range e {
+		fields[fieldErr.Field] = fieldErr.Message
+	}
+	return fields
+}
+
+// Limits of the numeric settings, in minutes or characters
+const (
+	MinRefreshInterval   = 1
+	MaxRefreshInterval   = 24 * 60
+	MaxNotificationTime  = 24 * 60
+	MinMeetingEndingTime = 1
+	MaxMeetingEndingTime = 24 * 60
+	MinMaxMeetings       = 1
+	MaxMaxMeetings       = 50
+	MinMaxTitleLength    = 5
+	MaxMaxTitleLength    = 200
+)
+
+// Variables that can be used in the tray title formats
+var (
+	CurrentMeetingFormatVariables  = []string{"title", "time_left", "start_time", "end_time"}
+	UpcomingMeetingFormatVariables = []string{"title", "time_until", "start_time", "end_time"}
+)
+
+var formatVariablePattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+var weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// Validate checks every setting and returns ValidationErrors listing the
+// invalid ones, or nil if the config is valid
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	checkRange := func(field string, value, min, max int) {
+		if value < min || value > max {
+			add(field, "must be between %d and %d, got %d", min, max, value)
+		}
+	}
+	checkRange("refresh_interval", c.RefreshInterval, MinRefreshInterval, MaxRefreshInterval)
+	checkRange("notification_time", c.NotificationTime, 0, MaxNotificationTime)
+	checkRange("meeting_ending_time", c.MeetingEndingTime, MinMeetingEndingTime, MaxMeetingEndingTime)
+	checkRange("max_meetings", c.MaxMeetings, MinMaxMeetings, MaxMaxMeetings)
+	checkRange("max_title_length", c.MaxTitleLength, MinMaxTitleLength, MaxMaxTitleLength)
+
+	checkChoice := func(field, value string, choices ...string) {
+		for _, choice := range choices {
+			if value == choice {
+				return
+			}
+		}
+		add(field, "must be one of %s, got %q", strings.Join(choices, ", "), value)
+	}
+	checkChoice("calendar_backend", c.CalendarBackend, "google", "gnome")
+	checkChoice("secret_store", c.SecretStore, SecretStoreAuto, SecretStoreKeyring, SecretStoreFile)
+	checkChoice("quiet_mode", c.QuietMode, QuietModeSilent, QuietModeSuppress)
+	checkChoice("autostart_method", c.AutostartMethod, AutostartDesktop, AutostartSystemd)
+
+	if err := checkFormat(c.CurrentMeetingFormat, CurrentMeetingFormatVariables); err != nil {
+		add("current_meeting_format", "%v", err)
+	}
+	if err := checkFormat(c.UpcomingMeetingFormat, UpcomingMeetingFormatVariables); err != nil {
+		add("upcoming_meeting_format", "%v", err)
+	}
+
+	checkPeriod := func(field string, days []string, start, end string) {
+		if !validClock(start) {
+			add(field+".start", "invalid time %q, expected HH:MM", start)
+		}
+		if !validClock(end) {
+			add(field+".end", "invalid time %q, expected HH:MM", end)
+		}
+		for _, day := range days {
+			if !validWeekday(day) {
+				add(field+".days", "unknown day %q, expected one of %s", day, strings.Join(weekdays, ", "))
+			}
+		}
+	}
+	for i, period := range c.QuietHours {
+		checkPeriod(fmt.Sprintf("quiet_hours[%d]", i), period.Days, period.Start, period.End)
+	}
+	for i, rule := range c.AlwaysAlert {
+		if rule.TitleContains == "" && rule.CalendarID == "" {
+			add(fmt.Sprintf("always_alert[%d]", i), "needs title_contains or calendar_id; an empty rule matches no meeting")
+		}
+	}
+
+	profileNames := make(map[string]bool)
+	for i, profile := range c.Profiles {
+		field := fmt.Sprintf("profiles[%d]", i)
+		switch {
+		case profile.Name == "":
+			add(field+".name", "must not be empty")
+		case profile.Name == AutomaticProfile:
+			add(field+".name", "%q is reserved for switching profiles by schedule", AutomaticProfile)
+		case profileNames[profile.Name]:
+			add(field+".name", "%q is used by another profile", profile.Name)
+		}
+		profileNames[profile.Name] = true
+
+		if profile.NotificationTime != nil {
+			checkRange(field+".notification_time", *profile.NotificationTime, 0, MaxNotificationTime)
+		}
+		if profile.MaxTitleLength != nil {
+			checkRange(field+".max_title_length", *profile.MaxTitleLength, MinMaxTitleLength, MaxMaxTitleLength)
+		}
+		if profile.CurrentMeetingFormat != nil {
+			if err := checkFormat(*profile.CurrentMeetingFormat, CurrentMeetingFormatVariables); err != nil {
+				add(field+".current_meeting_format", "%v", err)
+			}
+		}
+		if profile.UpcomingMeetingFormat != nil {
+			if err := checkFormat(*profile.UpcomingMeetingFormat, UpcomingMeetingFormatVariables); err != nil {
+				add(field+".upcoming_meeting_format", "%v", err)
+			}
+		}
+	}
+	if c.ActiveProfile != "" && !profileNames[c.ActiveProfile] {
+		add("active_profile", "no profile named %q", c.ActiveProfile)
+	}
+	for i, rule := range c.ProfileSchedule {
+		field := fmt.Sprintf("profile_schedule[%d]", i)
+		if !profileNames[rule.Profile] {
+			add(field+".profile", "no profile named %q", rule.Profile)
+		}
+		checkPeriod(field, rule.Days, rule.Start, rule.End)
+	}
+
+	clientNames := make(map[string]bool)
+	for i, client := range c.OAuthClients {
+		field := fmt.Sprintf("oauth_clients[%d]", i)
+		switch {
+		case client.Name == "":
+			add(field+".name", "must not be empty")
+		case clientNames[client.Name]:
+			add(field+".name", "%q is used by another client", client.Name)
+		}
+		clientNames[client.Name] = true
+		if client.ClientID == "" {
+			add(field+".client_id", "must not be empty")
+		}
+	}
+	for i, account := range c.Accounts {
+		if account.OAuthClient != "" && !clientNames[account.OAuthClient] {
+			add(fmt.Sprintf("accounts[%d].oauth_client", i), "no OAuth client named %q", account.OAuthClient)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkFormat reports unknown or unbalanced variables in a tray title format
+func checkFormat(format string, variables []string) error {
+	if strings.TrimSpace(format) == "" {
+		return fmt.Errorf("must not be empty")
+	}
+
+	for _, match := range formatVariablePattern.FindAllStringSubmatch(format, -1) {
+		if !containsString(variables, match[1]) {
+			return fmt.Errorf("unknown variable %s, available: {%s}", match[0], strings.Join(variables, "}, {"))
+		}
+	}
+
+	rest := formatVariablePattern.ReplaceAllString(format, "")
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("unbalanced braces, variables are written like {title}")
+	}
+	return nil
+}
+
+func validClock(value string) bool {
+	_, err := time.Parse("15:04", value)
+	return err == nil
+}
+
+// validWeekday accepts day names as matched by quiet hours: "mon", "Monday", ...
+func validWeekday(day string) bool {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for _, name := range weekdays {
+		if strings.HasPrefix(day, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// repair replaces the invalid settings that Load can fix with the nearest
+// valid value or the default, and logs a warning for each setting it changes
+// or cannot fix
+func (c *Config) repair() {
+	err := c.Validate()
+	if err == nil {
+		return
+	}
+
+	clamp := func(value *int, min, max int) interface{} {
+		if *value < min {
+			*value = min
+		} else if *value > max {
+			*value = max
+		}
+		return *value
+	}
+	reset := func(value *string, defaultValue string) interface{} {
+		*value = defaultValue
+		return fmt.Sprintf("%q", defaultValue)
+	}
+
+	for _, fieldErr := range err.(ValidationErrors) {
+		var used interface{}
+		switch fieldErr.Field {
+		case "refresh_interval":
+			used = clamp(&c.RefreshInterval, MinRefreshInterval, MaxRefreshInterval)
+		case "notification_time":
+			used = clamp(&c.NotificationTime, 0, MaxNotificationTime)
+		case "meeting_ending_time":
+			used = clamp(&c.MeetingEndingTime, MinMeetingEndingTime, MaxMeetingEndingTime)
+		case "max_meetings":
+			used = clamp(&c.MaxMeetings, MinMaxMeetings, MaxMaxMeetings)
+		case "max_title_length":
+			used = clamp(&c.MaxTitleLength, MinMaxTitleLength, MaxMaxTitleLength)
+		case "calendar_backend":
+			used = reset(&c.CalendarBackend, DefaultCalendarBackend)
+		case "secret_store":
+			used = reset(&c.SecretStore, DefaultSecretStore)
+		case "quiet_mode":
+			used = reset(&c.QuietMode, DefaultQuietMode)
+		case "autostart_method":
+			used = reset(&c.AutostartMethod, DefaultAutostartMethod)
+		case "current_meeting_format":
+			used = reset(&c.CurrentMeetingFormat, DefaultCurrentMeetingFormat)
+		case "upcoming_meeting_format":
+			used = reset(&c.UpcomingMeetingFormat, DefaultUpcomingMeetingFormat)
+		case "active_profile":
+			c.ActiveProfile = ""
+			used = "the profile schedule"
+		default:
+			if c.clearProfileSetting(fieldErr.Field) {
+				used = "the general setting"
+			}
+		}
+
+		if used != nil {
+			log.Printf("Warning: invalid setting %s; using %v", fieldErr, used)
+		} else {
+			log.Printf("Warning: invalid setting %s", fieldErr)
+		}
+	}
+}
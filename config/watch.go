@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const configFileName = "config.json"
+
+// configReloadDelay lets editors and tools finish writing before the file is read
+const configReloadDelay = 300 * time.Millisecond
+
+var (
+	// configMu serializes Load, Save and reloads, which share the global viper instance
+	configMu sync.Mutex
+
+	// lastWritten is the hash of config.json as this process last wrote it, so
+	// the watcher can tell its own saves from changes made by others
+	lastWritten [sha256.Size]byte
+)
+
+// rememberWrite records data as the content this process wrote to config.json.
+// Callers hold configMu.
+func rememberWrite(data []byte) {
+	lastWritten = sha256.Sum256(data)
+}
+
+// WatchConfig reloads the settings of store whenever config.json is
+// changed by another program, such as a dotfile manager, an editor or the GTK
+// settings window, until ctx is done. Saves made by this process are ignored,
+// and so is a file that cannot be loaded, e.g. one saved halfway through an edit.
+func WatchConfig(ctx context.Context, store *Store) error {
+	if err := ensureConfigDir(); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	configDir, err := getConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	// The directory is watched, since many tools replace the file instead of writing to it
+	if err := watcher.Add(configDir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if reload != nil {
+					reload.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != configFileName || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				if reload != nil {
+					reload.Stop()
+				}
+				reload = time.AfterFunc(configReloadDelay, func() {
+					store.reload(filepath.Join(configDir, configFileName))
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Config watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// loadChangedConfig loads the config file again unless it is the one this
+// process wrote last. It reports whether there were new settings to load.
+func loadChangedConfig(path string) (*Config, bool) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil || sha256.Sum256(data) == lastWritten {
+		return nil, false
+	}
+	if !json.Valid(data) {
+		log.Printf("Ignoring changed config file until it is valid JSON")
+		return nil, false
+	}
+
+	cfg, err := load()
+	if err != nil {
+		log.Printf("Ignoring changed config file: %v", err)
+		return nil, false
+	}
+	return cfg, true
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -11,21 +15,81 @@ import (
 )
 
 func main() {
+	configDir := flag.String("config", "", "use this directory for config.json instead of ~/.config/meetingbar (also $"+config.ConfigDirEnv+")")
+	profile := flag.String("profile", "", "switch to the named profile (\""+config.AutomaticProfile+"\" to switch by schedule) and exit; a running MeetingBar follows")
+	exportFile := flag.String("export", "", "export the settings to this file (.json or .toml, - for standard output) and exit")
+	importFile := flag.String("import", "", "import settings from this file after showing the changes, and exit")
+	importMode := flag.String("import-mode", config.ImportMerge, "\""+config.ImportMerge+"\" keeps settings missing from the imported file, \""+config.ImportReplace+"\" resets them")
+	assumeYes := flag.Bool("yes", false, "apply an import without asking")
+	openSettings := flag.Bool("settings", false, "open the settings, in the running MeetingBar if there is one")
+	refresh := flag.Bool("refresh", false, "refresh the meetings of the running MeetingBar, or start one")
+	joinNext := flag.Bool("join-next", false, "join the meeting in progress or the next one with a link")
+	flag.Parse()
+
+	command, err := trayCommand(*openSettings, *refresh, *joinNext)
+	if err != nil {
+		log.Fatalf("Invalid command: %v", err)
+	}
+
+	if *configDir != "" {
+		if err := config.SetConfigDir(*configDir); err != nil {
+			log.Fatalf("Invalid config directory: %v", err)
+		}
+	}
+
 	// Initialize configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Setup logging
-	if !cfg.Debug {
-		log.SetOutput(os.Stderr)
+	// Commands change or export the settings and exit; a running MeetingBar
+	// applies changes when it notices that config.json changed
+	switch {
+	case *profile != "":
+		if err := switchProfile(cfg, *profile); err != nil {
+			log.Fatalf("Failed to switch profile: %v", err)
+		}
+		return
+	case *exportFile != "":
+		if err := exportSettings(cfg, *exportFile); err != nil {
+			log.Fatalf("Failed to export settings: %v", err)
+		}
+		return
+	case *importFile != "":
+		if err := importSettings(cfg, *importFile, *importMode, *assumeYes); err != nil {
+			log.Fatalf("Failed to import settings: %v", err)
+		}
+		return
+	}
+
+	// A second launch hands its command to the running MeetingBar and exits,
+	// so there is one tray icon and one set of notifications
+	instance, err := ui.ClaimInstance()
+	if errors.Is(err, ui.ErrAlreadyRunning) {
+		if err := ui.ForwardCommand(command); err != nil {
+			log.Fatalf("Failed to pass the request to the running MeetingBar: %v", err)
+		}
+		if command == ui.CommandNone {
+			fmt.Println("MeetingBar is already running")
+		}
+		return
+	} else if err != nil {
+		log.Printf("Another MeetingBar may be running, it cannot be detected: %v", err)
+	}
+
+	// Setup logging: keep a copy in the state directory for bug reports
+	if logFile, err := config.OpenLogFile(); err != nil {
+		log.Printf("Logging to stderr only: %v", err)
+	} else {
+		defer logFile.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
 	}
 
 	// Run system tray
 	systray.Run(func() {
-		ui.OnReady(cfg)
+		ui.OnReady(config.NewStore(cfg), instance, command)
 	}, func() {
 		ui.OnExit()
 	})
-}
\ No newline at end of file
+}
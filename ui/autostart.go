@@ -0,0 +1,513 @@
+package ui
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"meetingbar/config"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	autostartStateFile = "autostart.json"
+
+	// systemdTarget starts the service with the desktop session, once the tray can be shown
+	systemdTarget = "graphical-session.target"
+
+	// autostartReloadDelay lets other programs finish writing the entry before it is read
+	autostartReloadDelay = 300 * time.Millisecond
+)
+
+// Kinds of autostart files, as kept in the autostart state
+const (
+	autostartDesktopEntry = "desktop"
+	autostartSystemdUnit  = "systemd"
+)
+
+// autostartRecord remembers an autostart file as MeetingBar last wrote or saw it
+type autostartRecord struct {
+	Checksum string `json:"checksum"`
+	// Edited is set for files written or changed by another program, which
+	// are switched on and off without replacing them
+	Edited bool `json:"edited,omitempty"`
+}
+
+// Autostart keeps the XDG autostart entry or the systemd user unit in line
+// with launch_at_login, and launch_at_login in line with changes that other
+// programs, such as the desktop's startup applications tool, make to the entry
+type Autostart struct {
+	store *config.Store
+	// unitName is the systemd user unit of this instance, see config.InstanceName
+	unitName string
+
+	mu        sync.Mutex
+	statePath string
+	records   map[string]autostartRecord
+}
+
+// NewAutostart loads what MeetingBar knows about the autostart files from the
+// state directory. A missing or unreadable state treats existing files as
+// written by another program.
+func NewAutostart(store *config.Store) *Autostart {
+	a := &Autostart{
+		store:   store,
+		records: make(map[string]autostartRecord),
+	}
+
+	name, err := config.InstanceName()
+	if err != nil {
+		log.Printf("Failed to name the autostart entry, launch at login is not managed: %v", err)
+		return a
+	}
+	a.unitName = name + ".service"
+
+	path, err := config.StateFile(autostartStateFile)
+	if err != nil {
+		log.Printf("Failed to get state directory, autostart edits will not be told apart: %v", err)
+		return a
+	}
+	a.statePath = path
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Failed to read autostart state: %v", err)
+	} else if err == nil {
+		if err := json.Unmarshal(data, &a.records); err != nil {
+			log.Printf("Failed to parse autostart state: %v", err)
+			a.records = make(map[string]autostartRecord)
+		}
+	}
+	return a
+}
+
+// Start takes over changes made to the autostart files while MeetingBar was
+// not running, brings them in line with the settings, and keeps doing so
+// until ctx is done
+func (a *Autostart) Start(ctx context.Context) {
+	if a.unitName == "" {
+		return
+	}
+	a.syncFromFiles()
+	a.apply(a.store.Get())
+	a.store.Subscribe(a.apply)
+
+	if err := a.watch(ctx); err != nil {
+		log.Printf("Changes to the autostart entry will be noticed at the next start: %v", err)
+	}
+}
+
+// apply writes, switches or removes the autostart files as cfg asks
+func (a *Autostart) apply(cfg *config.Config) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	useSystemd := cfg.LaunchAtLogin && cfg.AutostartMethod == config.AutostartSystemd
+	if err := a.setDesktopEntry(cfg.LaunchAtLogin && !useSystemd); err != nil {
+		log.Printf("Failed to update the autostart entry: %v", err)
+	}
+	if err := a.setSystemdUnit(useSystemd); err != nil {
+		log.Printf("Failed to update the systemd user service: %v", err)
+	}
+	a.saveRecords()
+}
+
+// setDesktopEntry writes or removes the autostart entry. An entry written or
+// changed by another program is kept and switched with Hidden instead.
+func (a *Autostart) setDesktopEntry(enabled bool) error {
+	path, err := config.AutostartFile()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	record, recorded := a.records[autostartDesktopEntry]
+	switch {
+	case exists && (!recorded || record.Edited || checksum(data) != record.Checksum):
+		updated := setDesktopEntryHidden(data, !enabled)
+		if string(updated) != string(data) {
+			if err := os.WriteFile(path, updated, 0644); err != nil {
+				return err
+			}
+		}
+		a.records[autostartDesktopEntry] = autostartRecord{Checksum: checksum(updated), Edited: true}
+	case enabled:
+		exec, err := launchCommand(desktopEntryQuote)
+		if err != nil {
+			return err
+		}
+		content := []byte(desktopEntry(exec))
+		if string(content) != string(data) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				return err
+			}
+			log.Printf("Added the autostart entry %s", path)
+		}
+		a.records[autostartDesktopEntry] = autostartRecord{Checksum: checksum(content)}
+	case exists:
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		delete(a.records, autostartDesktopEntry)
+		log.Printf("Removed the autostart entry %s", path)
+	}
+	return nil
+}
+
+// setSystemdUnit installs and enables the systemd user unit, or disables and
+// removes it. A unit written or changed by another program is only enabled or disabled.
+func (a *Autostart) setSystemdUnit(enabled bool) error {
+	unitDir, err := config.SystemdUnitDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(unitDir, a.unitName)
+	data, err := os.ReadFile(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	record, recorded := a.records[autostartSystemdUnit]
+	edited := exists && (!recorded || record.Edited || checksum(data) != record.Checksum)
+
+	if !enabled {
+		if !exists {
+			delete(a.records, autostartSystemdUnit)
+			return nil
+		}
+		if systemdUnitEnabled(unitDir, a.unitName) {
+			if err := systemctl("disable", a.unitName); err != nil {
+				return err
+			}
+		}
+		if edited {
+			a.records[autostartSystemdUnit] = autostartRecord{Checksum: checksum(data), Edited: true}
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		delete(a.records, autostartSystemdUnit)
+		log.Printf("Removed the systemd user service %s", path)
+		return systemctl("daemon-reload")
+	}
+
+	if !edited {
+		exec, err := launchCommand(systemdQuote)
+		if err != nil {
+			return err
+		}
+		content := []byte(systemdUnit(exec))
+		if string(content) != string(data) {
+			if err := os.MkdirAll(unitDir, 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				return err
+			}
+			if err := systemctl("daemon-reload"); err != nil {
+				return err
+			}
+			log.Printf("Added the systemd user service %s", path)
+		}
+		data = content
+	}
+	if !systemdUnitEnabled(unitDir, a.unitName) {
+		if err := systemctl("enable", a.unitName); err != nil {
+			return err
+		}
+	}
+	// Recorded only once enabled, so a unit that is disabled later was disabled by someone else
+	a.records[autostartSystemdUnit] = autostartRecord{Checksum: checksum(data), Edited: edited}
+	return nil
+}
+
+// syncFromFiles takes over changes that other programs made to the autostart
+// files: a removed or disabled entry turns launch_at_login off, and an entry
+// added or enabled elsewhere turns it on
+func (a *Autostart) syncFromFiles() {
+	a.mu.Lock()
+	enabled, changed := a.desktopEntryChange()
+	if unitEnabled, unitChanged := a.systemdUnitChange(); unitChanged {
+		enabled, changed = unitEnabled, true
+	}
+	a.saveRecords()
+	a.mu.Unlock()
+
+	if !changed || enabled == a.store.Get().LaunchAtLogin {
+		return
+	}
+
+	log.Printf("The autostart entry was changed by another program, launch at login is now %t", enabled)
+	err := a.store.Update(func(cfg *config.Config) error {
+		cfg.LaunchAtLogin = enabled
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to save the launch at login setting: %v", err)
+	}
+}
+
+// desktopEntryChange reports whether another program changed the autostart
+// entry since MeetingBar last saw it, and whether the entry now starts MeetingBar
+func (a *Autostart) desktopEntryChange() (enabled, changed bool) {
+	path, err := config.AutostartFile()
+	if err != nil {
+		return false, false
+	}
+	data, err := os.ReadFile(path)
+	record, recorded := a.records[autostartDesktopEntry]
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if !recorded {
+			return false, false
+		}
+		delete(a.records, autostartDesktopEntry)
+		return false, true
+	case err != nil:
+		log.Printf("Failed to read the autostart entry: %v", err)
+		return false, false
+	case recorded && checksum(data) == record.Checksum:
+		return false, false
+	}
+
+	a.records[autostartDesktopEntry] = autostartRecord{Checksum: checksum(data), Edited: true}
+	return desktopEntryEnabled(data), true
+}
+
+// systemdUnitChange reports whether the systemd user unit that MeetingBar
+// enabled was disabled or removed by someone else, e.g. with systemctl
+func (a *Autostart) systemdUnitChange() (enabled, changed bool) {
+	if _, recorded := a.records[autostartSystemdUnit]; !recorded {
+		return false, false
+	}
+	unitDir, err := config.SystemdUnitDir()
+	if err != nil {
+		return false, false
+	}
+	if systemdUnitEnabled(unitDir, a.unitName) {
+		return false, false
+	}
+	delete(a.records, autostartSystemdUnit)
+	return false, true
+}
+
+// watch calls syncFromFiles whenever the autostart entry changes, until ctx is done
+func (a *Autostart) watch(ctx context.Context) error {
+	path, err := config.AutostartFile()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create autostart directory: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create autostart watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch autostart directory: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if reload != nil {
+					reload.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Name != path {
+					continue
+				}
+				if reload != nil {
+					reload.Stop()
+				}
+				reload = time.AfterFunc(autostartReloadDelay, a.syncFromFiles)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Autostart watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// saveRecords writes what MeetingBar knows about the autostart files to the
+// state directory. Callers hold a.mu.
+func (a *Autostart) saveRecords() {
+	if a.statePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(a.records, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode autostart state: %v", err)
+		return
+	}
+	if err := config.EnsureStateDir(); err != nil {
+		log.Printf("Failed to create state directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(a.statePath, data, 0600); err != nil {
+		log.Printf("Failed to save autostart state: %v", err)
+	}
+}
+
+// launchCommand returns the command line that starts this MeetingBar, with a
+// custom config directory passed on, quoted by quote
+func launchCommand(quote func(string) string) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the MeetingBar executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	args := []string{executable}
+
+	configDir, err := config.CustomConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if configDir != "" {
+		args = append(args, "--config", configDir)
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+func desktopEntry(exec string) string {
+	return `[Desktop Entry]
+Type=Application
+Name=MeetingBar
+Comment=Upcoming meetings in the system tray
+Exec=` + exec + `
+Icon=meetingbar
+Terminal=false
+NoDisplay=true
+X-GNOME-Autostart-enabled=true
+`
+}
+
+func systemdUnit(exec string) string {
+	return `[Unit]
+Description=MeetingBar calendar tray
+PartOf=` + systemdTarget + `
+After=` + systemdTarget + `
+
+[Service]
+ExecStart=` + exec + `
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=` + systemdTarget + `
+`
+}
+
+// desktopEntryQuote quotes an argument for the Exec key of a desktop entry.
+// The backslashes escaping the quoted characters are doubled, since the value
+// of a desktop entry key is unescaped once more before the Exec rules apply.
+func desktopEntryQuote(arg string) string {
+	replacer := strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", `$`, `\\$`, `%`, `%%`)
+	return `"` + replacer.Replace(arg) + `"`
+}
+
+// systemdQuote quotes an argument for ExecStart, where % and $ start specifiers and variables
+func systemdQuote(arg string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`, `$`, `$$`)
+	return `"` + replacer.Replace(arg) + `"`
+}
+
+// desktopEntryEnabled reports whether an autostart entry is in effect: the
+// spec's Hidden and GNOME's X-GNOME-Autostart-enabled can both switch it off
+func desktopEntryEnabled(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "Hidden" && value == "true" || key == "X-GNOME-Autostart-enabled" && value == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// setDesktopEntryHidden switches an autostart entry on or off, keeping the rest of it as it is
+func setDesktopEntryHidden(data []byte, hidden bool) []byte {
+	if desktopEntryEnabled(data) != hidden {
+		return data
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, _, _ := strings.Cut(strings.TrimSpace(line), "=")
+		key = strings.TrimSpace(key)
+		if key == "Hidden" || key == "X-GNOME-Autostart-enabled" {
+			continue
+		}
+		lines = append(lines, line)
+		if hidden && strings.TrimSpace(line) == "[Desktop Entry]" {
+			lines = append(lines, "Hidden=true")
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// systemdUnitEnabled reports whether the unit of the given name in unitDir is enabled
+func systemdUnitEnabled(unitDir, name string) bool {
+	_, err := os.Lstat(filepath.Join(unitDir, systemdTarget+".wants", name))
+	return err == nil
+}
+
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -2,31 +2,40 @@ package gtk
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"log"
+	"os/exec"
 	"strconv"
+	"strings"
 
 	"meetingbar/calendar"
 	"meetingbar/config"
+	"meetingbar/ui/sound"
 
 	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
 )
 
 type GTKSettingsManager struct {
-	config          *config.Config
+	store           *config.Store
+	config          *config.Config // the settings being edited, saved to store by Save & Close
 	calendarService *calendar.UnifiedCalendarService
+	oauthSessions   *calendar.OAuthSessionManager
 	ctx             context.Context
-	onRefreshCallback func()
 	app             *gtk.Application
+	fieldEntries    map[string]*gtk.Entry // number entries by config.json key
 }
 
-func NewGTKSettingsManager(cfg *config.Config, ctx context.Context, onRefresh func()) *GTKSettingsManager {
+func NewGTKSettingsManager(store *config.Store, ctx context.Context) *GTKSettingsManager {
 	return &GTKSettingsManager{
-		config:            cfg,
-		calendarService:   calendar.NewUnifiedCalendarService(ctx, cfg),
-		ctx:               ctx,
-		onRefreshCallback: onRefresh,
+		store:           store,
+		calendarService: calendar.NewUnifiedCalendarService(ctx, store),
+		oauthSessions:   calendar.NewOAuthSessionManager(),
+		ctx:             ctx,
+		fieldEntries:    make(map[string]*gtk.Entry),
 	}
 }
 
@@ -64,6 +73,9 @@ func (gsm *GTKSettingsManager) ShowSettingsBlocking() error {
 }
 
 func (gsm *GTKSettingsManager) createMainWindow() {
+	// The window edits a copy, so other components never see half-made changes
+	gsm.config = gsm.store.Get()
+	
 	// Create main window
 	window := gtk.NewApplicationWindow(gsm.app)
 	window.SetTitle("MeetingBar Settings")
@@ -99,13 +111,19 @@ func (gsm *GTKSettingsManager) createMainWindow() {
 	saveBtn := gtk.NewButtonWithLabel("Save & Close")
 	saveBtn.AddCSSClass("suggested-action")
 	saveBtn.ConnectClicked(func() {
-		if err := gsm.config.Save(); err != nil {
+		if fieldErrors := gsm.validate(); len(fieldErrors) > 0 {
+			var messages []string
+			for _, fieldErr := range fieldErrors {
+				messages = append(messages, html.EscapeString(fieldErr.Error()))
+			}
+			gsm.showErrorDialog(window, "Please correct these settings", strings.Join(messages, "\n"))
+			return
+		}
+		
+		if err := gsm.store.Set(gsm.config); err != nil {
 			log.Printf("Failed to save config: %v", err)
 			gsm.showErrorDialog(window, "Failed to save configuration", err.Error())
 		} else {
-			if gsm.onRefreshCallback != nil {
-				gsm.onRefreshCallback()
-			}
 			window.Close()
 		}
 	})
@@ -177,6 +195,8 @@ func (gsm *GTKSettingsManager) addOAuth2Tab(notebook *gtk.Notebook) {
 		gsm.config.OAuth2.ClientSecret = clientSecretEntry.Text()
 	})
 	
+	markReadOnly("oauth2", clientIDEntry)
+	
 	// Add all elements to box
 	box.Append(titleLabel)
 	box.Append(instructionsLabel)
@@ -244,6 +264,8 @@ func (gsm *GTKSettingsManager) addBackendTab(notebook *gtk.Notebook) {
 		}
 	})
 	
+	markReadOnly("calendar_backend", googleRadio, gnomeRadio)
+	
 	// Add elements
 	box.Append(titleLabel)
 	box.Append(descLabel)
@@ -259,7 +281,6 @@ func (gsm *GTKSettingsManager) addBackendTab(notebook *gtk.Notebook) {
 }
 
 func (gsm *GTKSettingsManager) addAccountsTab(notebook *gtk.Notebook) {
-	// Create placeholder for now
 	box := gtk.NewBox(gtk.OrientationVertical, 20)
 	box.SetMarginTop(20)
 	box.SetMarginStart(20)
@@ -268,11 +289,164 @@ func (gsm *GTKSettingsManager) addAccountsTab(notebook *gtk.Notebook) {
 	
 	titleLabel := gtk.NewLabel("Google Accounts")
 	titleLabel.AddCSSClass("title-1")
+	titleLabel.SetHAlign(gtk.AlignStart)
+	
+	// Connected accounts
+	accountsBox := gtk.NewBox(gtk.OrientationVertical, 5)
+	var accountLabels []*gtk.Label
+	refreshAccounts := func() {
+		for _, label := range accountLabels {
+			accountsBox.Remove(label)
+		}
+		accountLabels = nil
+		
+		texts := []string{"No accounts connected yet."}
+		if len(gsm.config.Accounts) > 0 {
+			texts = nil
+			for _, account := range gsm.config.Accounts {
+				texts = append(texts, fmt.Sprintf("👤 %s (added %s)", account.Email, account.AddedAt.Format("Jan 2, 2006")))
+			}
+		}
+		for _, text := range texts {
+			label := gtk.NewLabel(text)
+			label.SetHAlign(gtk.AlignStart)
+			accountsBox.Append(label)
+			accountLabels = append(accountLabels, label)
+		}
+	}
+	refreshAccounts()
+	
+	addBtn := gtk.NewButtonWithLabel("+ Add Google Account")
+	addBtn.AddCSSClass("suggested-action")
+	codeBtn := gtk.NewButtonWithLabel("📟 Sign in with a code")
+	cancelBtn := gtk.NewButtonWithLabel("Cancel sign-in")
+	cancelBtn.SetVisible(false)
+	
+	// Accounts can use one of the additional OAuth clients from config.json
+	clientNames := []string{"Default client"}
+	for _, client := range gsm.config.OAuthClients {
+		clientNames = append(clientNames, client.Name)
+	}
+	clientDropDown := gtk.NewDropDownFromStrings(clientNames)
+	clientDropDown.SetVisible(len(gsm.config.OAuthClients) > 0)
+	selectedClient := func() string {
+		if i := clientDropDown.Selected(); i > 0 && int(i) <= len(gsm.config.OAuthClients) {
+			return gsm.config.OAuthClients[i-1].Name
+		}
+		return ""
+	}
+	
+	buttonBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
+	buttonBox.Append(clientDropDown)
+	buttonBox.Append(addBtn)
+	buttonBox.Append(codeBtn)
+	buttonBox.Append(cancelBtn)
+	
+	// Progress of the current sign-in
+	statusLabel := gtk.NewLabel("")
+	statusLabel.SetWrap(true)
+	statusLabel.SetSelectable(true)
+	statusLabel.SetHAlign(gtk.AlignStart)
+	statusLabel.SetVisible(false)
+	
+	var sessionID string
+	setRunning := func(running bool) {
+		addBtn.SetSensitive(!running)
+		codeBtn.SetSensitive(!running)
+		clientDropDown.SetSensitive(!running)
+		cancelBtn.SetVisible(running)
+	}
+	
+	// pollSession runs on the GTK main loop until the sign-in has finished
+	pollSession := func() bool {
+		session, ok := gsm.oauthSessions.Status(sessionID)
+		if !ok {
+			setRunning(false)
+			return false
+		}
+		
+		switch session.Status {
+		case calendar.OAuthWaiting:
+			return true
+		case calendar.OAuthSucceeded:
+			// The account is saved right away, whether or not the other changes are
+			gsm.config.Accounts = append(gsm.config.Accounts, *session.Account)
+			err := gsm.store.Update(func(cfg *config.Config) error {
+				cfg.Accounts = append(cfg.Accounts, *session.Account)
+				return nil
+			})
+			if err != nil {
+				log.Printf("Failed to save config after adding account: %v", err)
+				statusLabel.SetText("❌ Sign-in failed: " + err.Error())
+			} else {
+				statusLabel.SetText("✅ Added " + session.Account.Email)
+				if warning := calendar.MissingScopesWarning(session.Account); warning != "" {
+					statusLabel.SetText("⚠️ Added " + session.Account.Email + ", but: " + warning)
+				}
+				refreshAccounts()
+			}
+		case calendar.OAuthCancelled:
+			statusLabel.SetText("Sign-in cancelled.")
+		default:
+			statusLabel.SetText("❌ Sign-in " + session.Describe())
+		}
+		
+		setRunning(false)
+		return false
+	}
 	
-	placeholderLabel := gtk.NewLabel("Account management will be implemented here.")
+	startSession := func(start func(context.Context, *config.Config, string, calendar.OAuthFinishFunc) (calendar.OAuthSession, error)) (calendar.OAuthSession, bool) {
+		session, err := start(gsm.ctx, gsm.config, selectedClient(), nil)
+		statusLabel.SetVisible(true)
+		if err != nil {
+			statusLabel.SetText("❌ " + err.Error())
+			return session, false
+		}
+		
+		sessionID = session.ID
+		setRunning(true)
+		glib.TimeoutAdd(1000, pollSession)
+		return session, true
+	}
+	
+	addBtn.ConnectClicked(func() {
+		session, ok := startSession(gsm.oauthSessions.StartBrowser)
+		if !ok {
+			return
+		}
+		
+		statusLabel.SetText("⏳ Waiting for you to finish signing in with Google in your browser...")
+		if err := exec.Command("xdg-open", session.AuthURL).Start(); err != nil {
+			log.Printf("Failed to open browser: %v", err)
+			statusLabel.SetText("⏳ Open this address in your browser to sign in:\n" + session.AuthURL)
+		}
+	})
+	
+	codeBtn.ConnectClicked(func() {
+		session, ok := startSession(gsm.oauthSessions.StartDevice)
+		if !ok {
+			return
+		}
+		
+		statusLabel.SetText(fmt.Sprintf("⏳ Open %s on any device and enter the code %s\nThe code expires at %s.",
+			session.VerificationURL, session.UserCode, session.ExpiresAt.Local().Format("15:04")))
+	})
+	
+	cancelBtn.ConnectClicked(func() {
+		if err := gsm.oauthSessions.Cancel(sessionID); err != nil {
+			log.Printf("Failed to cancel sign-in: %v", err)
+		}
+	})
+	
+	storeLabel := gtk.NewLabel("🔐 Sign-in tokens are stored in: " + config.ActiveSecretStore().Description())
+	storeLabel.SetHAlign(gtk.AlignStart)
 	
 	box.Append(titleLabel)
-	box.Append(placeholderLabel)
+	box.Append(accountsBox)
+	box.Append(gtk.NewSeparator(gtk.OrientationHorizontal))
+	box.Append(buttonBox)
+	box.Append(statusLabel)
+	box.Append(storeLabel)
 	
 	tabLabel := gtk.NewLabel("👤 Accounts")
 	notebook.AppendPage(box, tabLabel)
@@ -324,13 +498,7 @@ func (gsm *GTKSettingsManager) addNotificationsTab(notebook *gtk.Notebook) {
 	
 	// Notification time
 	notifTimeLabel := gtk.NewLabel("Minutes before meeting:")
-	notifTimeEntry := gtk.NewEntry()
-	notifTimeEntry.SetText(strconv.Itoa(gsm.config.NotificationTime))
-	notifTimeEntry.ConnectChanged(func() {
-		if val, err := strconv.Atoi(notifTimeEntry.Text()); err == nil {
-			gsm.config.NotificationTime = val
-		}
-	})
+	notifTimeEntry := gsm.numberEntry("notification_time", &gsm.config.NotificationTime)
 	
 	notifTimeBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
 	notifTimeBox.Append(notifTimeLabel)
@@ -343,6 +511,57 @@ func (gsm *GTKSettingsManager) addNotificationsTab(notebook *gtk.Notebook) {
 		gsm.config.NotificationSound = soundCheck.Active()
 	})
 	
+	// Custom sound files per reminder type
+	soundFilesLabel := gtk.NewLabel("Custom sound files (leave empty for the sound theme):")
+	soundFilesLabel.SetHAlign(gtk.AlignStart)
+	
+	soundFilesBox := gtk.NewBox(gtk.OrientationVertical, 5)
+	soundFilesBox.Append(soundFilesLabel)
+	for _, row := range []struct {
+		reminderType string
+		label        string
+		themeSound   string
+	}{
+		{"start", "Upcoming meeting:", sound.ThemeMessageNewInstant},
+		{"ending", "Meeting ending:", sound.ThemeMessageNewInstant},
+		{"overlap", "Next meeting started:", sound.ThemeAlarmClockElapsed},
+		{"interruptive", "Join prompt:", sound.ThemeAlarmClockElapsed},
+		{"change", "Meeting changes:", sound.ThemeMessageNewInstant},
+	} {
+		row := row
+		
+		fileEntry := gtk.NewEntry()
+		fileEntry.SetHExpand(true)
+		fileEntry.SetPlaceholderText("Default: " + row.themeSound)
+		fileEntry.SetText(gsm.config.SoundFiles[row.reminderType])
+		fileEntry.ConnectChanged(func() {
+			if gsm.config.SoundFiles == nil {
+				gsm.config.SoundFiles = make(map[string]string)
+			}
+			if file := fileEntry.Text(); file != "" {
+				gsm.config.SoundFiles[row.reminderType] = file
+			} else {
+				delete(gsm.config.SoundFiles, row.reminderType)
+			}
+		})
+		
+		testBtn := gtk.NewButtonWithLabel("Test")
+		testBtn.ConnectClicked(func() {
+			testSound := sound.Sound{Name: row.themeSound, File: fileEntry.Text()}
+			if err := testSound.Play(); err != nil {
+				log.Printf("Failed to play test sound: %v", err)
+			}
+		})
+		
+		markReadOnly("sound_files", fileEntry)
+		
+		rowBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
+		rowBox.Append(gtk.NewLabel(row.label))
+		rowBox.Append(fileEntry)
+		rowBox.Append(testBtn)
+		soundFilesBox.Append(rowBox)
+	}
+	
 	// Persistent notifications
 	persistentCheck := gtk.NewCheckButtonWithLabel("Persistent notifications")
 	persistentCheck.SetActive(gsm.config.PersistentNotifications)
@@ -350,12 +569,120 @@ func (gsm *GTKSettingsManager) addNotificationsTab(notebook *gtk.Notebook) {
 		gsm.config.PersistentNotifications = persistentCheck.Active()
 	})
 	
+	// Interruptive reminders
+	interruptiveCheck := gtk.NewCheckButtonWithLabel("Full-screen join prompt when a meeting starts")
+	interruptiveCheck.SetActive(gsm.config.InterruptiveReminders)
+	interruptiveCheck.ConnectToggled(func() {
+		gsm.config.InterruptiveReminders = interruptiveCheck.Active()
+	})
+	
+	// Quiet time
+	dndCheck := gtk.NewCheckButtonWithLabel("Respect the desktop's Do Not Disturb mode")
+	dndCheck.SetActive(gsm.config.RespectDoNotDisturb)
+	dndCheck.ConnectToggled(func() {
+		gsm.config.RespectDoNotDisturb = dndCheck.Active()
+	})
+	
+	suppressCheck := gtk.NewCheckButtonWithLabel("Hide reminders during quiet time instead of showing them silently")
+	suppressCheck.SetActive(gsm.config.QuietMode == config.QuietModeSuppress)
+	suppressCheck.ConnectToggled(func() {
+		if suppressCheck.Active() {
+			gsm.config.QuietMode = config.QuietModeSuppress
+		} else {
+			gsm.config.QuietMode = config.QuietModeSilent
+		}
+	})
+	
+	quietHoursLabel := gtk.NewLabel(fmt.Sprintf("%d quiet hours schedule(s), %d always-alert rule(s); edit them in the web settings",
+		len(gsm.config.QuietHours), len(gsm.config.AlwaysAlert)))
+	quietHoursLabel.SetHAlign(gtk.AlignStart)
+	quietHoursLabel.AddCSSClass("dim-label")
+	
+	// Meeting end notifications
+	endingCheck := gtk.NewCheckButtonWithLabel("Remind me before the current meeting ends")
+	endingCheck.SetActive(gsm.config.NotifyMeetingEnding)
+	endingCheck.ConnectToggled(func() {
+		gsm.config.NotifyMeetingEnding = endingCheck.Active()
+	})
+	
+	endingTimeLabel := gtk.NewLabel("Minutes before meeting ends:")
+	endingTimeEntry := gsm.numberEntry("meeting_ending_time", &gsm.config.MeetingEndingTime)
+	
+	endingTimeBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
+	endingTimeBox.Append(endingTimeLabel)
+	endingTimeBox.Append(endingTimeEntry)
+	
+	overlapCheck := gtk.NewCheckButtonWithLabel("Alert when the next meeting starts while I'm still in one")
+	overlapCheck.SetActive(gsm.config.NotifyOverlap)
+	overlapCheck.ConnectToggled(func() {
+		gsm.config.NotifyOverlap = overlapCheck.Active()
+	})
+	
+	// Meeting change notifications
+	changesLabel := gtk.NewLabel("Notify about meeting changes:")
+	changesLabel.SetHAlign(gtk.AlignStart)
+	
+	rescheduledCheck := gtk.NewCheckButtonWithLabel("Rescheduled meetings")
+	rescheduledCheck.SetActive(gsm.config.NotifyRescheduled)
+	rescheduledCheck.ConnectToggled(func() {
+		gsm.config.NotifyRescheduled = rescheduledCheck.Active()
+	})
+	
+	cancelledCheck := gtk.NewCheckButtonWithLabel("Cancelled meetings")
+	cancelledCheck.SetActive(gsm.config.NotifyCancelled)
+	cancelledCheck.ConnectToggled(func() {
+		gsm.config.NotifyCancelled = cancelledCheck.Active()
+	})
+	
+	linkChangedCheck := gtk.NewCheckButtonWithLabel("Changed meeting links")
+	linkChangedCheck.SetActive(gsm.config.NotifyLinkChanged)
+	linkChangedCheck.ConnectToggled(func() {
+		gsm.config.NotifyLinkChanged = linkChangedCheck.Active()
+	})
+	
+	newMeetingsCheck := gtk.NewCheckButtonWithLabel("New meetings added for today")
+	newMeetingsCheck.SetActive(gsm.config.NotifyNewMeetings)
+	newMeetingsCheck.ConnectToggled(func() {
+		gsm.config.NotifyNewMeetings = newMeetingsCheck.Active()
+	})
+	
+	markReadOnly("enable_notifications", enableNotificationsCheck)
+	markReadOnly("notification_time", notifTimeEntry)
+	markReadOnly("notification_sound", soundCheck)
+	markReadOnly("persistent_notifications", persistentCheck)
+	markReadOnly("interruptive_reminders", interruptiveCheck)
+	markReadOnly("respect_do_not_disturb", dndCheck)
+	markReadOnly("quiet_mode", suppressCheck)
+	markReadOnly("notify_meeting_ending", endingCheck)
+	markReadOnly("meeting_ending_time", endingTimeEntry)
+	markReadOnly("notify_overlap", overlapCheck)
+	markReadOnly("notify_rescheduled", rescheduledCheck)
+	markReadOnly("notify_cancelled", cancelledCheck)
+	markReadOnly("notify_link_changed", linkChangedCheck)
+	markReadOnly("notify_new_meetings", newMeetingsCheck)
+	
 	// Add elements
 	box.Append(titleLabel)
 	box.Append(enableNotificationsCheck)
 	box.Append(notifTimeBox)
 	box.Append(soundCheck)
+	box.Append(soundFilesBox)
 	box.Append(persistentCheck)
+	box.Append(interruptiveCheck)
+	box.Append(gtk.NewSeparator(gtk.OrientationHorizontal))
+	box.Append(dndCheck)
+	box.Append(suppressCheck)
+	box.Append(quietHoursLabel)
+	box.Append(gtk.NewSeparator(gtk.OrientationHorizontal))
+	box.Append(endingCheck)
+	box.Append(endingTimeBox)
+	box.Append(overlapCheck)
+	box.Append(gtk.NewSeparator(gtk.OrientationHorizontal))
+	box.Append(changesLabel)
+	box.Append(rescheduledCheck)
+	box.Append(cancelledCheck)
+	box.Append(linkChangedCheck)
+	box.Append(newMeetingsCheck)
 	
 	scrolled.SetChild(box)
 	
@@ -383,13 +710,7 @@ func (gsm *GTKSettingsManager) addGeneralTab(notebook *gtk.Notebook) {
 	
 	// Refresh interval
 	refreshLabel := gtk.NewLabel("Refresh interval (minutes):")
-	refreshEntry := gtk.NewEntry()
-	refreshEntry.SetText(strconv.Itoa(gsm.config.RefreshInterval))
-	refreshEntry.ConnectChanged(func() {
-		if val, err := strconv.Atoi(refreshEntry.Text()); err == nil {
-			gsm.config.RefreshInterval = val
-		}
-	})
+	refreshEntry := gsm.numberEntry("refresh_interval", &gsm.config.RefreshInterval)
 	
 	refreshBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
 	refreshBox.Append(refreshLabel)
@@ -397,13 +718,7 @@ func (gsm *GTKSettingsManager) addGeneralTab(notebook *gtk.Notebook) {
 	
 	// Max meetings
 	maxMeetingsLabel := gtk.NewLabel("Max meetings to show:")
-	maxMeetingsEntry := gtk.NewEntry()
-	maxMeetingsEntry.SetText(strconv.Itoa(gsm.config.MaxMeetings))
-	maxMeetingsEntry.ConnectChanged(func() {
-		if val, err := strconv.Atoi(maxMeetingsEntry.Text()); err == nil {
-			gsm.config.MaxMeetings = val
-		}
-	})
+	maxMeetingsEntry := gsm.numberEntry("max_meetings", &gsm.config.MaxMeetings)
 	
 	maxMeetingsBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
 	maxMeetingsBox.Append(maxMeetingsLabel)
@@ -423,12 +738,39 @@ func (gsm *GTKSettingsManager) addGeneralTab(notebook *gtk.Notebook) {
 		gsm.config.ShowMeetingLinks = showLinksCheck.Active()
 	})
 	
+	// Launch at login
+	launchCheck := gtk.NewCheckButtonWithLabel("Launch at login")
+	launchCheck.SetActive(gsm.config.LaunchAtLogin)
+	launchCheck.ConnectToggled(func() {
+		gsm.config.LaunchAtLogin = launchCheck.Active()
+	})
+	
+	systemdCheck := gtk.NewCheckButtonWithLabel("Start as a systemd user service (restarted if it crashes)")
+	systemdCheck.SetActive(gsm.config.AutostartMethod == config.AutostartSystemd)
+	systemdCheck.SetMarginStart(20)
+	systemdCheck.ConnectToggled(func() {
+		if systemdCheck.Active() {
+			gsm.config.AutostartMethod = config.AutostartSystemd
+		} else {
+			gsm.config.AutostartMethod = config.AutostartDesktop
+		}
+	})
+	
+	markReadOnly("refresh_interval", refreshEntry)
+	markReadOnly("max_meetings", maxMeetingsEntry)
+	markReadOnly("show_duration", showDurationCheck)
+	markReadOnly("show_meeting_links", showLinksCheck)
+	markReadOnly("launch_at_login", launchCheck)
+	markReadOnly("autostart_method", systemdCheck)
+	
 	// Add elements
 	box.Append(titleLabel)
 	box.Append(refreshBox)
 	box.Append(maxMeetingsBox)
 	box.Append(showDurationCheck)
 	box.Append(showLinksCheck)
+	box.Append(launchCheck)
+	box.Append(systemdCheck)
 	
 	scrolled.SetChild(box)
 	
@@ -437,6 +779,62 @@ func (gsm *GTKSettingsManager) addGeneralTab(notebook *gtk.Notebook) {
 	notebook.AppendPage(scrolled, tabLabel)
 }
 
+// numberEntry edits an integer setting. Text that is not a number is not
+// applied and is reported when saving.
+func (gsm *GTKSettingsManager) numberEntry(field string, value *int) *gtk.Entry {
+	entry := gtk.NewEntry()
+	entry.SetText(strconv.Itoa(*value))
+	entry.ConnectChanged(func() {
+		if val, err := strconv.Atoi(entry.Text()); err == nil {
+			*value = val
+		}
+	})
+	gsm.fieldEntries[field] = entry
+	return entry
+}
+
+// markReadOnly makes the widgets of a setting that cannot be changed here,
+// e.g. one locked by an administrator, insensitive and says why in their tooltip
+func markReadOnly(key string, widgets ...gtk.Widgetter) {
+	reason, ok := config.ReadOnlySettings()[key]
+	if !ok {
+		return
+	}
+	for _, widget := range widgets {
+		base := gtk.BaseWidget(widget)
+		base.SetSensitive(false)
+		base.SetTooltipText("🔒 " + reason)
+	}
+}
+
+// validate checks the settings as entered and marks the entries of invalid settings
+func (gsm *GTKSettingsManager) validate() config.ValidationErrors {
+	var fieldErrors config.ValidationErrors
+	for field, entry := range gsm.fieldEntries {
+		if _, err := strconv.Atoi(entry.Text()); err != nil {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: field, Message: fmt.Sprintf("%q is not a whole number", entry.Text())})
+		}
+	}
+	if err := gsm.config.Validate(); err != nil {
+		var configErrors config.ValidationErrors
+		if errors.As(err, &configErrors) {
+			fieldErrors = append(fieldErrors, configErrors...)
+		}
+	}
+	
+	messages := fieldErrors.Fields()
+	for field, entry := range gsm.fieldEntries {
+		if message, ok := messages[field]; ok {
+			entry.AddCSSClass("error")
+			entry.SetTooltipText(message)
+		} else {
+			entry.RemoveCSSClass("error")
+			entry.SetTooltipText("")
+		}
+	}
+	return fieldErrors
+}
+
 func (gsm *GTKSettingsManager) showErrorDialog(parent *gtk.ApplicationWindow, title, message string) {
 	// Use MessageDialog for GTK4 compatibility
 	dialog := gtk.NewMessageDialog(
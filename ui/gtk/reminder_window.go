@@ -0,0 +1,135 @@
+package gtk
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// ReminderAction is the user's response to the interruptive reminder window
+type ReminderAction string
+
+const (
+	ReminderActionNone    ReminderAction = ""
+	ReminderActionJoin    ReminderAction = "join"
+	ReminderActionSnooze  ReminderAction = "snooze"
+	ReminderActionDismiss ReminderAction = "dismiss"
+)
+
+// ReminderDetails describes the meeting shown in the reminder window
+type ReminderDetails struct {
+	Title     string
+	StartTime time.Time
+	EndTime   time.Time
+	Attendees []string
+	HasLink   bool
+}
+
+// maxReminderAttendees keeps the attendee list readable for large meetings
+const maxReminderAttendees = 8
+
+// ShowReminderWindowBlocking shows a full-screen join prompt and blocks until the
+// user picks an action or closes the window. GTK 4 has no keep-above hint, so
+// the window is made full-screen to make sure it is not buried behind an editor.
+func ShowReminderWindowBlocking(details ReminderDetails) (ReminderAction, error) {
+	action := ReminderActionNone
+
+	app := gtk.NewApplication("com.meetingbar.reminder", gio.ApplicationNonUnique)
+	app.ConnectActivate(func() {
+		window := gtk.NewApplicationWindow(app)
+		window.SetTitle("Meeting starting: " + details.Title)
+		window.SetDefaultSize(800, 500)
+
+		respond := func(chosen ReminderAction) {
+			action = chosen
+			window.Close()
+		}
+
+		window.SetChild(buildReminderContent(details, respond))
+		window.Fullscreen()
+		window.Present()
+	})
+
+	status := app.Run(nil)
+	if status != 0 {
+		return ReminderActionNone, fmt.Errorf("GTK reminder window exited with status %d", status)
+	}
+
+	return action, nil
+}
+
+func buildReminderContent(details ReminderDetails, respond func(ReminderAction)) *gtk.Box {
+	box := gtk.NewBox(gtk.OrientationVertical, 20)
+	box.SetHAlign(gtk.AlignCenter)
+	box.SetVAlign(gtk.AlignCenter)
+	box.SetMarginTop(40)
+	box.SetMarginStart(40)
+	box.SetMarginEnd(40)
+	box.SetMarginBottom(40)
+
+	headerLabel := gtk.NewLabel("Your meeting is starting")
+	headerLabel.AddCSSClass("title-2")
+
+	titleLabel := gtk.NewLabel(details.Title)
+	titleLabel.AddCSSClass("title-1")
+	titleLabel.SetWrap(true)
+	titleLabel.SetJustify(gtk.JustifyCenter)
+
+	timeLabel := gtk.NewLabel(fmt.Sprintf("%s – %s",
+		details.StartTime.Format("15:04"),
+		details.EndTime.Format("15:04")))
+	timeLabel.AddCSSClass("title-4")
+
+	box.Append(headerLabel)
+	box.Append(titleLabel)
+	box.Append(timeLabel)
+
+	if len(details.Attendees) > 0 {
+		attendeesLabel := gtk.NewLabel("")
+		attendeesLabel.SetMarkup("<b>Attendees:</b> " + glib.MarkupEscapeText(formatAttendees(details.Attendees)))
+		attendeesLabel.SetWrap(true)
+		attendeesLabel.SetJustify(gtk.JustifyCenter)
+		box.Append(attendeesLabel)
+	}
+
+	buttonBox := gtk.NewBox(gtk.OrientationHorizontal, 10)
+	buttonBox.SetHAlign(gtk.AlignCenter)
+	buttonBox.SetMarginTop(20)
+
+	if details.HasLink {
+		joinBtn := gtk.NewButtonWithLabel("Join Meeting")
+		joinBtn.AddCSSClass("suggested-action")
+		joinBtn.ConnectClicked(func() {
+			respond(ReminderActionJoin)
+		})
+		buttonBox.Append(joinBtn)
+	}
+
+	snoozeBtn := gtk.NewButtonWithLabel("Snooze")
+	snoozeBtn.ConnectClicked(func() {
+		respond(ReminderActionSnooze)
+	})
+
+	dismissBtn := gtk.NewButtonWithLabel("Dismiss")
+	dismissBtn.ConnectClicked(func() {
+		respond(ReminderActionDismiss)
+	})
+
+	buttonBox.Append(snoozeBtn)
+	buttonBox.Append(dismissBtn)
+	box.Append(buttonBox)
+
+	return box
+}
+
+func formatAttendees(attendees []string) string {
+	if len(attendees) <= maxReminderAttendees {
+		return strings.Join(attendees, ", ")
+	}
+	shown := strings.Join(attendees[:maxReminderAttendees], ", ")
+	return fmt.Sprintf("%s and %d more", shown, len(attendees)-maxReminderAttendees)
+}
@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"meetingbar/config"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+const (
+	// InstanceBusName is the session bus name owned by the running MeetingBar.
+	// One using a custom config directory owns a name of its own, see instanceBusName.
+	InstanceBusName = "com.meetingbar.MeetingBar"
+
+	instanceObjectPath = dbus.ObjectPath("/com/meetingbar/MeetingBar")
+	instanceInterface  = "com.meetingbar.MeetingBar"
+)
+
+// Command is a request that a launch of MeetingBar hands to the running instance
+type Command string
+
+// Commands that can be given on the command line
+const (
+	CommandNone         Command = ""
+	CommandOpenSettings Command = "settings"
+	CommandRefresh      Command = "refresh"
+	CommandJoinNext     Command = "join-next"
+)
+
+// instanceMethods maps commands to the methods of the D-Bus interface
+var instanceMethods = map[Command]string{
+	CommandNone:         "Activate",
+	CommandOpenSettings: "OpenSettings",
+	CommandRefresh:      "Refresh",
+	CommandJoinNext:     "JoinNext",
+}
+
+// ErrAlreadyRunning is returned by ClaimInstance when another MeetingBar owns the bus name
+var ErrAlreadyRunning = errors.New("MeetingBar is already running")
+
+// Instance owns the instance bus name for the lifetime of MeetingBar and takes
+// commands forwarded by later launches
+type Instance struct {
+	conn *dbus.Conn
+
+	mu      sync.Mutex
+	handle  func(Command) error
+	pending []Command
+}
+
+// ClaimInstance makes this MeetingBar the running instance. It returns
+// ErrAlreadyRunning when another one already is.
+func ClaimInstance() (*Instance, error) {
+	busName, err := instanceBusName()
+	if err != nil {
+		return nil, err
+	}
+
+	// A private connection, so the name is released when it is closed
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	instance := &Instance{conn: conn}
+	service := &instanceService{instance: instance}
+
+	// Exported before the name is claimed, so a launch that finds the name can call it right away
+	if err := conn.Export(service, instanceObjectPath, instanceInterface); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to export instance object: %w", err)
+	}
+	node := &introspect.Node{
+		Name: string(instanceObjectPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{Name: instanceInterface, Methods: introspect.Methods(service)},
+		},
+	}
+	if err := conn.Export(introspect.NewIntrospectable(node), instanceObjectPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to export introspection data: %w", err)
+	}
+
+	reply, err := conn.RequestName(busName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to request %s: %w", busName, err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return nil, ErrAlreadyRunning
+	}
+	return instance, nil
+}
+
+// ForwardCommand hands command to the running instance
+func ForwardCommand(command Command) error {
+	method, ok := instanceMethods[command]
+	if !ok {
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	busName, err := instanceBusName()
+	if err != nil {
+		return err
+	}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+	defer conn.Close()
+
+	call := conn.Object(busName, instanceObjectPath).Call(instanceInterface+"."+method, 0)
+	if call.Err != nil {
+		var dbusErr dbus.Error
+		if errors.As(call.Err, &dbusErr) && len(dbusErr.Body) > 0 {
+			return fmt.Errorf("%v", dbusErr.Body[0])
+		}
+		return call.Err
+	}
+	return nil
+}
+
+// instanceBusName returns the bus name for the config directory in use. An
+// isolated instance, started with --config or MEETINGBAR_CONFIG_DIR, gets a
+// name derived from its directory, so it runs next to the usual one and only
+// takes the commands of launches with the same directory.
+func instanceBusName() (string, error) {
+	id, err := config.ConfigDirID()
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return InstanceBusName, nil
+	}
+	// Name elements must not start with a digit
+	return InstanceBusName + ".Config_" + id, nil
+}
+
+// serve runs commands with handle, starting with those that arrived before
+// MeetingBar was ready. A nil Instance, when the bus is unavailable, does nothing.
+func (i *Instance) serve(handle func(Command) error) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	i.handle = handle
+	pending := i.pending
+	i.pending = nil
+	i.mu.Unlock()
+
+	for _, command := range pending {
+		if err := handle(command); err != nil {
+			log.Printf("Failed to run forwarded command %q: %v", command, err)
+		}
+	}
+}
+
+// run runs a forwarded command, or keeps it until MeetingBar is ready
+func (i *Instance) run(command Command) *dbus.Error {
+	i.mu.Lock()
+	handle := i.handle
+	if handle == nil {
+		i.pending = append(i.pending, command)
+	}
+	i.mu.Unlock()
+
+	log.Printf("Received command %q from another launch", command)
+	if handle == nil {
+		return nil
+	}
+	if err := handle(command); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	return nil
+}
+
+// Close releases the bus name
+func (i *Instance) Close() {
+	if i != nil {
+		i.conn.Close()
+	}
+}
+
+// instanceService holds the methods exported on the session bus
+type instanceService struct {
+	instance *Instance
+}
+
+// Activate is called by a launch without a command
+func (s *instanceService) Activate() *dbus.Error {
+	return s.instance.run(CommandNone)
+}
+
+func (s *instanceService) OpenSettings() *dbus.Error {
+	return s.instance.run(CommandOpenSettings)
+}
+
+func (s *instanceService) Refresh() *dbus.Error {
+	return s.instance.run(CommandRefresh)
+}
+
+func (s *instanceService) JoinNext() *dbus.Error {
+	return s.instance.run(CommandJoinNext)
+}
@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"meetingbar/calendar"
+)
+
+// MeetingChangeKind identifies how a meeting differs between two refreshes
+type MeetingChangeKind string
+
+const (
+	MeetingRescheduled MeetingChangeKind = "rescheduled"
+	MeetingCancelled   MeetingChangeKind = "cancelled"
+	MeetingLinkChanged MeetingChangeKind = "link_changed"
+	MeetingAdded       MeetingChangeKind = "added"
+)
+
+// MeetingChange describes a single difference between two meeting snapshots
+type MeetingChange struct {
+	Kind     MeetingChangeKind
+	Meeting  calendar.Meeting
+	Previous calendar.Meeting
+}
+
+// diffMeetings compares two consecutive meeting snapshots. Only calendars
+// that were read in both refreshes are compared, keyed by calendarKey, so a
+// calendar that failed or was just enabled or disabled reports nothing.
+// Meetings that have already ended are ignored. A meeting that is no longer
+// returned, e.g. because it moved out of the fetched window, is not reported:
+// only meetings the calendar marks as cancelled are announced as cancelled.
+func diffMeetings(previous, current []calendar.Meeting, previousFetched, currentFetched map[string]bool, now time.Time) []MeetingChange {
+	var changes []MeetingChange
+
+	compared := func(meeting calendar.Meeting) bool {
+		key := calendarKey(meeting.AccountID, meeting.CalendarID)
+		return meeting.ID != "" && previousFetched[key] && currentFetched[key]
+	}
+
+	previousByKey := make(map[string]calendar.Meeting, len(previous))
+	for _, meeting := range previous {
+		if compared(meeting) {
+			previousByKey[meeting.Key()] = meeting
+		}
+	}
+
+	for _, meeting := range current {
+		if !compared(meeting) {
+			continue
+		}
+		old, existed := previousByKey[meeting.Key()]
+
+		if meeting.Cancelled {
+			// Announced once, while the previous refresh still had it as scheduled
+			if existed && now.Before(old.StartTime) {
+				changes = append(changes, MeetingChange{Kind: MeetingCancelled, Meeting: old, Previous: old})
+			}
+			continue
+		}
+
+		if !now.Before(meeting.EndTime) {
+			continue
+		}
+
+		if !existed {
+			if meeting.StartTime.After(now) && isSameDay(meeting.StartTime, now) {
+				changes = append(changes, MeetingChange{Kind: MeetingAdded, Meeting: meeting})
+			}
+			continue
+		}
+
+		if !old.StartTime.Equal(meeting.StartTime) {
+			changes = append(changes, MeetingChange{Kind: MeetingRescheduled, Meeting: meeting, Previous: old})
+		}
+		if meetingLinkURL(old) != meetingLinkURL(meeting) {
+			changes = append(changes, MeetingChange{Kind: MeetingLinkChanged, Meeting: meeting, Previous: old})
+		}
+	}
+
+	return changes
+}
+
+// calendarKey identifies a calendar of an account in the sets of calendars a refresh read
+func calendarKey(accountID, calendarID string) string {
+	return accountID + "|" + calendarID
+}
+
+// notificationText returns the title and message used to announce the change
+func (c MeetingChange) notificationText(now time.Time) (string, string) {
+	switch c.Kind {
+	case MeetingRescheduled:
+		return "Meeting Rescheduled", fmt.Sprintf("%s rescheduled from %s to %s",
+			c.Meeting.Title,
+			formatChangeTime(c.Previous.StartTime, now),
+			formatChangeTime(c.Meeting.StartTime, now))
+	case MeetingCancelled:
+		return "Meeting Cancelled", fmt.Sprintf("%s at %s was cancelled",
+			c.Meeting.Title,
+			formatChangeTime(c.Meeting.StartTime, now))
+	case MeetingLinkChanged:
+		if c.Meeting.MeetingLink == nil {
+			return "Meeting Link Changed", fmt.Sprintf("%s no longer has a meeting link", c.Meeting.Title)
+		}
+		return "Meeting Link Changed", fmt.Sprintf("%s has a new %s link", c.Meeting.Title, c.Meeting.MeetingLink.Type)
+	default:
+		return "New Meeting Today", fmt.Sprintf("%s added at %s",
+			c.Meeting.Title,
+			formatChangeTime(c.Meeting.StartTime, now))
+	}
+}
+
+func meetingLinkURL(meeting calendar.Meeting) string {
+	if meeting.MeetingLink == nil {
+		return ""
+	}
+	return meeting.MeetingLink.URL
+}
+
+func isSameDay(a, b time.Time) bool {
+	a = a.Local()
+	b = b.Local()
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// formatChangeTime shows only the clock time for today, and includes the weekday otherwise
+func formatChangeTime(t, now time.Time) string {
+	if isSameDay(t, now) {
+		return t.Local().Format("15:04")
+	}
+	return t.Local().Format("Mon 15:04")
+}
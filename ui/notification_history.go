@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"meetingbar/calendar"
+	"meetingbar/config"
+)
+
+// HistoryAction records what happened to a reminder after it was shown
+type HistoryAction string
+
+const (
+	HistoryPending   HistoryAction = ""
+	HistoryJoined    HistoryAction = "joined"
+	HistorySnoozed   HistoryAction = "snoozed"
+	HistoryDismissed HistoryAction = "dismissed"
+	HistoryExpired   HistoryAction = "expired"
+)
+
+const (
+	notificationHistoryFile = "notification_history.json"
+	maxHistoryEntries       = 200
+	historyRetention        = 7 * 24 * time.Hour
+)
+
+// HistoryEntry is one reminder that was shown to the user
+type HistoryEntry struct {
+	Key          string        `json:"key"`
+	MeetingID    string        `json:"meeting_id"`
+	MeetingTitle string        `json:"meeting_title"`
+	StartTime    time.Time     `json:"start_time"`
+	EndTime      time.Time     `json:"end_time"`
+	Type         ReminderType  `json:"type"`
+	SentAt       time.Time     `json:"sent_at"`
+	Action       HistoryAction `json:"action,omitempty"`
+	ActionAt     time.Time     `json:"action_at,omitempty"`
+	Resurfaced   bool          `json:"resurfaced,omitempty"`
+}
+
+// NotificationHistory is a log of recent reminders kept in the state directory,
+// oldest first
+type NotificationHistory struct {
+	mu      sync.Mutex
+	path    string
+	entries []HistoryEntry
+}
+
+// NewNotificationHistory loads the reminder history from the state directory.
+// A missing or unreadable file results in an empty history.
+func NewNotificationHistory() *NotificationHistory {
+	history := &NotificationHistory{}
+
+	path, err := config.StateFile(notificationHistoryFile)
+	if err != nil {
+		log.Printf("Failed to get state directory, reminder history will not persist: %v", err)
+		return history
+	}
+	history.path = path
+
+	if err := history.load(); err != nil {
+		log.Printf("Failed to load reminder history: %v", err)
+	}
+
+	return history
+}
+
+// Record adds a reminder that was just shown for the meeting
+func (h *NotificationHistory) Record(meeting *calendar.Meeting, reminderType ReminderType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, HistoryEntry{
+		Key:          reminderKey(meeting, reminderType),
+		MeetingID:    meeting.ID,
+		MeetingTitle: meeting.Title,
+		StartTime:    meeting.StartTime,
+		EndTime:      meeting.EndTime,
+		Type:         reminderType,
+		SentAt:       time.Now(),
+	})
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+
+	h.saveLocked()
+}
+
+// SetAction records the user's response on the latest reminder of the given type for the meeting
+func (h *NotificationHistory) SetAction(meeting *calendar.Meeting, reminderType ReminderType, action HistoryAction) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry := h.latestLocked(reminderKey(meeting, reminderType)); entry != nil {
+		entry.Action = action
+		entry.ActionAt = time.Now()
+		h.saveLocked()
+	}
+}
+
+// MarkResurfaced records that a missed reminder has been shown again
+func (h *NotificationHistory) MarkResurfaced(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry := h.latestLocked(key); entry != nil {
+		entry.Resurfaced = true
+		h.saveLocked()
+	}
+}
+
+func (h *NotificationHistory) latestLocked(key string) *HistoryEntry {
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].Key == key {
+			return &h.entries[i]
+		}
+	}
+	return nil
+}
+
+// Recent returns up to limit entries, newest first; a limit of 0 returns all of them
+func (h *NotificationHistory) Recent(limit int) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	recent := make([]HistoryEntry, len(h.entries))
+	copy(recent, h.entries)
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].SentAt.After(recent[j].SentAt)
+	})
+
+	if limit > 0 && len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
+// Missed returns the reminders sent since the given time that got no response,
+// for meetings that have not ended yet. Change notifications are left out.
+func (h *NotificationHistory) Missed(since, now time.Time) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var missed []HistoryEntry
+	for _, entry := range h.entries {
+		if entry.Type == ReminderChange || entry.Action != HistoryPending || entry.Resurfaced {
+			continue
+		}
+		if entry.SentAt.Before(since) || !now.Before(entry.EndTime) {
+			continue
+		}
+		missed = append(missed, entry)
+	}
+	return missed
+}
+
+// Expire marks unanswered reminders for meetings that have ended as expired
+// and drops entries past the retention period. It reports whether any entry changed.
+func (h *NotificationHistory) Expire(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	changed := false
+	kept := h.entries[:0]
+	for _, entry := range h.entries {
+		if now.Sub(entry.SentAt) > historyRetention {
+			changed = true
+			continue
+		}
+		if entry.Action == HistoryPending && !now.Before(entry.EndTime) {
+			entry.Action = HistoryExpired
+			entry.ActionAt = entry.EndTime
+			changed = true
+		}
+		kept = append(kept, entry)
+	}
+	h.entries = kept
+
+	if changed {
+		h.saveLocked()
+	}
+	return changed
+}
+
+func (h *NotificationHistory) load() error {
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read reminder history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return fmt.Errorf("failed to parse reminder history: %w", err)
+	}
+	return nil
+}
+
+// saveLocked writes the history atomically and logs failures; callers must hold h.mu
+func (h *NotificationHistory) saveLocked() {
+	if h.path == "" {
+		return
+	}
+
+	if err := config.EnsureStateDir(); err != nil {
+		log.Printf("Failed to create state directory: %v", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		log.Printf("Failed to marshal reminder history: %v", err)
+		return
+	}
+
+	tmpPath := h.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		log.Printf("Failed to write reminder history: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, h.path); err != nil {
+		log.Printf("Failed to write reminder history: %v", err)
+	}
+}
+
+// findMeeting looks up a meeting instance by ID and start time
+func findMeeting(meetings []calendar.Meeting, id string, startTime time.Time) *calendar.Meeting {
+	for i := range meetings {
+		if meetings[i].ID == id && meetings[i].StartTime.Equal(startTime) {
+			return &meetings[i]
+		}
+	}
+	return nil
+}
+
+// historyTypeLabel is the human readable name of a reminder type
+func historyTypeLabel(reminderType ReminderType) string {
+	switch reminderType {
+	case ReminderStart:
+		return "Upcoming"
+	case ReminderEnding:
+		return "Ending soon"
+	case ReminderOverlap:
+		return "Overlap"
+	case ReminderInterruptive:
+		return "Join prompt"
+	case ReminderChange:
+		return "Change"
+	default:
+		return string(reminderType)
+	}
+}
+
+// historyActionLabel is the human readable name of a reminder action
+func historyActionLabel(action HistoryAction) string {
+	if action == HistoryPending {
+		return "no response"
+	}
+	return string(action)
+}
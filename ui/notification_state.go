@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"meetingbar/calendar"
+	"meetingbar/config"
+)
+
+// ReminderStatus describes what happened to the reminder for a meeting instance
+type ReminderStatus string
+
+const (
+	ReminderDelivered ReminderStatus = "delivered"
+	ReminderSnoozed   ReminderStatus = "snoozed"
+	ReminderDismissed ReminderStatus = "dismissed"
+)
+
+// ReminderType distinguishes the different reminders sent for one meeting
+type ReminderType string
+
+const (
+	ReminderStart   ReminderType = "start"
+	ReminderEnding  ReminderType = "ending"
+	ReminderOverlap ReminderType = "overlap"
+	// ReminderInterruptive is the prominent join prompt shown at start time
+	ReminderInterruptive ReminderType = "interruptive"
+	// ReminderChange covers meeting change notifications; it selects a sound
+	// but is never stored, since changes are only announced once
+	ReminderChange ReminderType = "change"
+)
+
+const notificationStateFile = "notification_state.json"
+
+// ReminderState is the persisted reminder state for a single meeting instance
+type ReminderState struct {
+	Status       ReminderStatus `json:"status"`
+	SnoozedUntil time.Time      `json:"snoozed_until,omitempty"`
+	ExpiresAt    time.Time      `json:"expires_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// NotificationStateStore keeps reminder state in the state directory so that
+// restarting MeetingBar does not re-send reminders that were already handled
+type NotificationStateStore struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]ReminderState
+}
+
+// NewNotificationStateStore loads the reminder state from the state directory.
+// A missing or unreadable file results in an empty store.
+func NewNotificationStateStore() *NotificationStateStore {
+	store := &NotificationStateStore{
+		entries: make(map[string]ReminderState),
+	}
+
+	path, err := config.StateFile(notificationStateFile)
+	if err != nil {
+		log.Printf("Failed to get state directory, reminder state will not persist: %v", err)
+		return store
+	}
+	store.path = path
+
+	if err := store.load(); err != nil {
+		log.Printf("Failed to load reminder state: %v", err)
+	}
+	store.Expire(time.Now())
+
+	return store
+}
+
+// reminderKey identifies one reminder for a meeting instance. The start time is
+// part of the key so a rescheduled instance of a recurring event is treated as
+// a new reminder.
+func reminderKey(meeting *calendar.Meeting, reminderType ReminderType) string {
+	return string(reminderType) + ":" + meeting.ID + "@" + meeting.StartTime.UTC().Format(time.RFC3339)
+}
+
+// Lookup returns the stored state for a reminder of a meeting instance
+func (s *NotificationStateStore) Lookup(meeting *calendar.Meeting, reminderType ReminderType) (ReminderState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.entries[reminderKey(meeting, reminderType)]
+	return state, ok
+}
+
+// MarkDelivered records that a reminder was shown for the meeting
+func (s *NotificationStateStore) MarkDelivered(meeting *calendar.Meeting, reminderType ReminderType) {
+	s.set(meeting, reminderType, ReminderState{Status: ReminderDelivered})
+}
+
+// Snooze records that the reminder should be shown again at the given time
+func (s *NotificationStateStore) Snooze(meeting *calendar.Meeting, reminderType ReminderType, until time.Time) {
+	s.set(meeting, reminderType, ReminderState{Status: ReminderSnoozed, SnoozedUntil: until})
+}
+
+// Dismiss records that the user does not want this reminder again for the meeting
+func (s *NotificationStateStore) Dismiss(meeting *calendar.Meeting, reminderType ReminderType) {
+	s.set(meeting, reminderType, ReminderState{Status: ReminderDismissed})
+}
+
+func (s *NotificationStateStore) set(meeting *calendar.Meeting, reminderType ReminderType, state ReminderState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.ExpiresAt = meeting.EndTime
+	state.UpdatedAt = time.Now()
+	s.entries[reminderKey(meeting, reminderType)] = state
+
+	if err := s.save(); err != nil {
+		log.Printf("Failed to save reminder state: %v", err)
+	}
+}
+
+// Expire drops state for meetings that have already ended
+func (s *NotificationStateStore) Expire(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for key, state := range s.entries {
+		if !now.Before(state.ExpiresAt) {
+			delete(s.entries, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := s.save(); err != nil {
+			log.Printf("Failed to save reminder state: %v", err)
+		}
+	}
+}
+
+func (s *NotificationStateStore) load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read reminder state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return fmt.Errorf("failed to parse reminder state: %w", err)
+	}
+	return nil
+}
+
+// save writes the state atomically; callers must hold s.mu
+func (s *NotificationStateStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	if err := config.EnsureStateDir(); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reminder state: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write reminder state: %w", err)
+	}
+	return os.Rename(tmpPath, s.path)
+}
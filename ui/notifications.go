@@ -1,72 +1,277 @@
 package ui
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"os/exec"
+	"strings"
+	"sync"
 	"time"
 
 	"meetingbar/calendar"
 	"meetingbar/config"
+	"meetingbar/ui/sound"
 
 	"github.com/gen2brain/beeep"
 )
 
+// recentStartWindow limits start-time alerts to meetings that started recently,
+// so launching MeetingBar in the middle of a meeting does not trigger one
+const recentStartWindow = 5 * time.Minute
+
+// reminderSnoozeDuration is how long "Snooze" postpones a reminder
+const reminderSnoozeDuration = 1 * time.Minute
+
+// Action identifiers shared by notify-send actions and the GTK reminder window
+const (
+	actionJoin    = "join"
+	actionSnooze  = "snooze"
+	actionDismiss = "dismiss"
+	actionReauth  = "reauth"
+)
+
 type NotificationManager struct {
-	config          *config.Config
+	store           *config.Store
+	mu              sync.Mutex // guards meetings and runs one reminder check at a time
 	meetings        []calendar.Meeting
-	notifiedMeetings map[string]bool
+	state           *NotificationStateStore
+	history         *NotificationHistory
+	onHistoryChange func()
 }
 
-func NewNotificationManager(cfg *config.Config) *NotificationManager {
+func NewNotificationManager(store *config.Store) *NotificationManager {
 	return &NotificationManager{
-		config:           cfg,
-		notifiedMeetings: make(map[string]bool),
+		store:   store,
+		state:   NewNotificationStateStore(),
+		history: NewNotificationHistory(),
+	}
+}
+
+// SetHistoryChangedCallback registers a function called whenever the reminder history changes
+func (nm *NotificationManager) SetHistoryChangedCallback(callback func()) {
+	nm.onHistoryChange = callback
+}
+
+// RecentReminders returns up to limit reminders from the history, newest first
+func (nm *NotificationManager) RecentReminders(limit int) []HistoryEntry {
+	return nm.history.Recent(limit)
+}
+
+func (nm *NotificationManager) historyChanged() {
+	if nm.onHistoryChange != nil {
+		nm.onHistoryChange()
 	}
 }
 
 func (nm *NotificationManager) UpdateMeetings(meetings []calendar.Meeting) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
 	nm.meetings = meetings
 	nm.checkForUpcomingMeetings()
 }
 
+// checkForUpcomingMeetings sends the reminders that are due; callers must hold nm.mu
 func (nm *NotificationManager) checkForUpcomingMeetings() {
-	if !nm.config.EnableNotifications {
+	cfg := nm.store.Effective()
+	now := time.Now()
+
+	// Drop state for meetings that have ended, even when notifications are off
+	nm.state.Expire(now)
+	if nm.history.Expire(now) {
+		nm.historyChanged()
+	}
+
+	if !cfg.EnableNotifications {
 		return
 	}
 
-	now := time.Now()
-	notificationTime := nm.config.GetNotificationDuration()
+	notificationTime := cfg.GetNotificationDuration()
+
+	for i := range nm.meetings {
+		meeting := &nm.meetings[i]
+
+		state, seen := nm.state.Lookup(meeting, ReminderStart)
+		if !seen {
+			// Check if meeting is within notification window
+			timeUntilMeeting := meeting.StartTime.Sub(now)
+			if timeUntilMeeting <= notificationTime && timeUntilMeeting > 0 {
+				nm.sendMeetingNotification(meeting)
+				nm.state.MarkDelivered(meeting, ReminderStart)
+			}
+			continue
+		}
+
+		// Re-send snoozed reminders once the snooze elapses, as long as the meeting is still on
+		if state.Status == ReminderSnoozed && !now.Before(state.SnoozedUntil) && now.Before(meeting.EndTime) {
+			nm.sendMeetingNotification(meeting)
+			nm.state.MarkDelivered(meeting, ReminderStart)
+		}
+	}
+
+	nm.checkCurrentMeeting(now)
+
+	if cfg.InterruptiveReminders {
+		nm.checkInterruptiveReminders(now)
+	}
+}
+
+// checkInterruptiveReminders shows the prominent join prompt once a meeting has
+// started, and again after a snooze for as long as the meeting is running
+func (nm *NotificationManager) checkInterruptiveReminders(now time.Time) {
+	for i := range nm.meetings {
+		meeting := &nm.meetings[i]
+		if now.Before(meeting.StartTime) || !now.Before(meeting.EndTime) {
+			continue
+		}
 
-	for _, meeting := range nm.meetings {
-		// Skip if already notified
-		if nm.notifiedMeetings[meeting.ID] {
+		state, seen := nm.state.Lookup(meeting, ReminderInterruptive)
+		due := !seen && now.Sub(meeting.StartTime) <= recentStartWindow
+		if seen && state.Status == ReminderSnoozed && !now.Before(state.SnoozedUntil) {
+			due = true
+		}
+		if !due {
 			continue
 		}
 
-		// Check if meeting is within notification window
-		timeUntilMeeting := meeting.StartTime.Sub(now)
-		if timeUntilMeeting <= notificationTime && timeUntilMeeting > 0 {
-			nm.sendMeetingNotification(&meeting)
-			nm.notifiedMeetings[meeting.ID] = true
+		nm.state.MarkDelivered(meeting, ReminderInterruptive)
+
+		// Never take over the screen during quiet time; notify downgrades or suppresses instead
+		if nm.quietLevelFor(meeting, now) != quietNone {
+			nm.notify(ReminderInterruptive, meeting, "Meeting Started", meeting.Title+" has started", meeting)
+			continue
 		}
+		nm.recordReminder(meeting, ReminderInterruptive)
+		nm.showInterruptiveReminder(*meeting)
 	}
+}
 
-	// Clean up old notifications (meetings that have passed)
-	for meetingID := range nm.notifiedMeetings {
-		found := false
-		for _, meeting := range nm.meetings {
-			if meeting.ID == meetingID && now.Before(meeting.EndTime) {
-				found = true
-				break
+// handleReminderAction applies the user's response to a reminder
+func (nm *NotificationManager) handleReminderAction(meeting calendar.Meeting, reminderType ReminderType, action string) {
+	switch action {
+	case actionJoin:
+		log.Printf("Joining meeting from reminder: %s", meeting.Title)
+		openMeetingLink(&meeting)
+		nm.history.SetAction(&meeting, reminderType, HistoryJoined)
+	case actionSnooze:
+		log.Printf("Snoozed reminder for meeting: %s", meeting.Title)
+		nm.SnoozeMeeting(&meeting, reminderType, reminderSnoozeDuration)
+		nm.history.SetAction(&meeting, reminderType, HistorySnoozed)
+	case actionDismiss:
+		log.Printf("Dismissed reminder for meeting: %s", meeting.Title)
+		nm.DismissMeeting(&meeting, reminderType)
+		nm.history.SetAction(&meeting, reminderType, HistoryDismissed)
+	default:
+		return
+	}
+	nm.historyChanged()
+}
+
+// recordReminder adds a shown reminder to the history. Test notifications use
+// meetings without an ID and are not recorded.
+func (nm *NotificationManager) recordReminder(meeting *calendar.Meeting, reminderType ReminderType) {
+	if meeting == nil || meeting.ID == "" {
+		return
+	}
+	nm.history.Record(meeting, reminderType)
+	nm.historyChanged()
+}
+
+// resurfaceMissedReminders shows reminders that went unanswered while the
+// screen was locked again, once per meeting, for meetings that are still on
+func (nm *NotificationManager) resurfaceMissedReminders(lockedAt, now time.Time) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	resurfaced := make(map[string]bool)
+	for _, entry := range nm.history.Missed(lockedAt, now) {
+		nm.history.MarkResurfaced(entry.Key)
+
+		meeting := findMeeting(nm.meetings, entry.MeetingID, entry.StartTime)
+		if meeting == nil || resurfaced[meeting.ID] {
+			continue
+		}
+		resurfaced[meeting.ID] = true
+
+		title := "Missed Reminder"
+		message := fmt.Sprintf("%s (%s - %s)", meeting.Title,
+			meeting.StartTime.Format("15:04"), meeting.EndTime.Format("15:04"))
+		log.Printf("Re-surfacing missed %s reminder for meeting: %s", entry.Type, meeting.Title)
+		nm.notify(entry.Type, meeting, title, message, meeting)
+	}
+}
+
+// checkCurrentMeeting sends the optional "ending soon" reminder for the meeting in
+// progress, and an alert with a Join action when the next meeting starts while
+// the previous one is still running or has only just ended
+func (nm *NotificationManager) checkCurrentMeeting(now time.Time) {
+	cfg := nm.store.Effective()
+	currentMeeting, upcomingMeetings := splitMeetings(nm.meetings, now)
+	if currentMeeting == nil {
+		return
+	}
+
+	if cfg.NotifyMeetingEnding {
+		timeLeft := currentMeeting.EndTime.Sub(now)
+		if _, seen := nm.state.Lookup(currentMeeting, ReminderEnding); !seen && timeLeft > 0 && timeLeft <= cfg.GetMeetingEndingDuration() {
+			var nextMeeting *calendar.Meeting
+			if len(upcomingMeetings) > 0 && !upcomingMeetings[0].StartTime.After(currentMeeting.EndTime.Add(cfg.GetMeetingEndingDuration())) {
+				nextMeeting = &upcomingMeetings[0]
 			}
+			nm.sendEndingNotification(currentMeeting, nextMeeting, timeLeft)
+			nm.state.MarkDelivered(currentMeeting, ReminderEnding)
 		}
-		if !found {
-			delete(nm.notifiedMeetings, meetingID)
+	}
+
+	if cfg.NotifyOverlap && now.Sub(currentMeeting.StartTime) <= recentStartWindow {
+		if previousMeeting := findOverrunMeeting(nm.meetings, currentMeeting); previousMeeting != nil {
+			if _, seen := nm.state.Lookup(currentMeeting, ReminderOverlap); !seen {
+				title := "Next Meeting Started"
+				message := fmt.Sprintf("%s has started while you are in %s", currentMeeting.Title, previousMeeting.Title)
+				nm.notify(ReminderOverlap, currentMeeting, title, message, currentMeeting)
+				nm.state.MarkDelivered(currentMeeting, ReminderOverlap)
+			}
 		}
 	}
 }
 
+// findOverrunMeeting returns an earlier meeting that is still running when the
+// given meeting starts, or that ends exactly as it starts (back-to-back)
+func findOverrunMeeting(meetings []calendar.Meeting, meeting *calendar.Meeting) *calendar.Meeting {
+	for i := range meetings {
+		other := &meetings[i]
+		if other.ID == meeting.ID && other.StartTime.Equal(meeting.StartTime) {
+			continue
+		}
+		if other.StartTime.Before(meeting.StartTime) && !other.EndTime.Before(meeting.StartTime) {
+			return other
+		}
+	}
+	return nil
+}
+
+func (nm *NotificationManager) sendEndingNotification(meeting, nextMeeting *calendar.Meeting, timeLeft time.Duration) {
+	title := "Meeting Ending Soon"
+	message := fmt.Sprintf("%s ends in %s", meeting.Title, formatDuration(timeLeft))
+	if nextMeeting != nil {
+		message += fmt.Sprintf("\nNext: %s at %s", nextMeeting.Title, nextMeeting.StartTime.Format("15:04"))
+	}
+
+	// Offer to join the next meeting rather than the one that is ending
+	nm.notify(ReminderEnding, meeting, title, message, nextMeeting)
+}
+
+// SnoozeMeeting suppresses a reminder for a meeting until the snooze duration has passed
+func (nm *NotificationManager) SnoozeMeeting(meeting *calendar.Meeting, reminderType ReminderType, d time.Duration) {
+	nm.state.Snooze(meeting, reminderType, time.Now().Add(d))
+}
+
+// DismissMeeting stops any further reminders of the given type for a meeting
+func (nm *NotificationManager) DismissMeeting(meeting *calendar.Meeting, reminderType ReminderType) {
+	nm.state.Dismiss(meeting, reminderType)
+}
+
 func (nm *NotificationManager) sendMeetingNotification(meeting *calendar.Meeting) {
 	now := time.Now()
 	timeUntil := meeting.StartTime.Sub(now)
@@ -84,79 +289,325 @@ func (nm *NotificationManager) sendMeetingNotification(meeting *calendar.Meeting
 	title := "Upcoming Meeting"
 	message := fmt.Sprintf("%s %s", meeting.Title, timeText)
 
-	// Try to send notification with action button if meeting has a link
-	if meeting.MeetingLink != nil {
-		nm.sendNotificationWithAction(title, message, meeting)
-	} else {
-		// Send simple notification
-		err := beeep.Notify(title, message, "")
-		if err != nil {
-			log.Printf("Failed to send notification: %v", err)
+	nm.notify(ReminderStart, meeting, title, message, meeting)
+}
+
+// NotifyMeetingChanges announces changes detected between two meeting refreshes,
+// honouring the per-kind notification settings
+func (nm *NotificationManager) NotifyMeetingChanges(changes []MeetingChange) {
+	cfg := nm.store.Effective()
+	if !cfg.EnableNotifications {
+		return
+	}
+
+	now := time.Now()
+	for i := range changes {
+		change := &changes[i]
+		if !nm.changeNotificationEnabled(change.Kind) {
+			continue
 		}
+
+		title, message := change.notificationText(now)
+		log.Printf("Meeting change detected (%s): %s", change.Kind, message)
+		nm.notify(ReminderChange, &change.Meeting, title, message, &change.Meeting)
 	}
 }
 
-func (nm *NotificationManager) sendNotificationWithAction(title, message string, meeting *calendar.Meeting) {
-	// Try to use native Linux desktop notifications with actions
-	// This varies by desktop environment, so we'll try a few approaches
-	
-	// First try with notify-send (most common)
-	if nm.tryNotifySend(title, message, meeting) {
+func (nm *NotificationManager) changeNotificationEnabled(kind MeetingChangeKind) bool {
+	cfg := nm.store.Effective()
+	switch kind {
+	case MeetingRescheduled:
+		return cfg.NotifyRescheduled
+	case MeetingCancelled:
+		return cfg.NotifyCancelled
+	case MeetingLinkChanged:
+		return cfg.NotifyLinkChanged
+	case MeetingAdded:
+		return cfg.NotifyNewMeetings
+	default:
+		return false
+	}
+}
+
+// notify shows a notification about meeting and plays the sound for its reminder
+// type, unless quiet hours or Do Not Disturb are active. joinMeeting may be nil,
+// otherwise its link is offered as a Join action.
+func (nm *NotificationManager) notify(reminderType ReminderType, meeting *calendar.Meeting, title, message string, joinMeeting *calendar.Meeting) {
+	quiet := nm.quietLevelFor(meeting, time.Now())
+	if quiet == quietSuppress {
+		log.Printf("Suppressed notification during quiet time: %s", title)
 		return
 	}
-	
-	// Fallback to simple notification
-	err := beeep.Notify(title, message, "")
-	if err != nil {
-		log.Printf("Failed to send notification: %v", err)
+
+	var hints []string
+	if quiet == quietNone {
+		hints = nm.playReminderSound(reminderType)
+	}
+
+	onJoin := func() {
+		if meeting != nil {
+			nm.history.SetAction(meeting, reminderType, HistoryJoined)
+			nm.historyChanged()
+		}
+	}
+
+	// notify-send supports actions and sound hints, so prefer it
+	if !nm.tryNotifySend(title, message, joinMeeting, hints, quiet == quietSilent, onJoin) {
+		// Fallback to simple notification
+		if err := beeep.Notify(title, message, ""); err != nil {
+			log.Printf("Failed to send notification: %v", err)
+			return
+		}
 	}
+
+	nm.recordReminder(meeting, reminderType)
 }
 
-func (nm *NotificationManager) tryNotifySend(title, message string, meeting *calendar.Meeting) bool {
-	// Check if notify-send is available
-	if _, err := exec.LookPath("notify-send"); err != nil {
-		return false
+// reminderSound returns the sound for a reminder type: an alarm for reminders
+// that need attention right away, a message sound otherwise, or the custom
+// file configured for that type
+func reminderSound(cfg *config.Config, reminderType ReminderType) sound.Sound {
+	name := sound.ThemeMessageNewInstant
+	if reminderType == ReminderInterruptive || reminderType == ReminderOverlap {
+		name = sound.ThemeAlarmClockElapsed
+	}
+	return sound.Sound{Name: name, File: cfg.SoundFiles[string(reminderType)]}
+}
+
+// playReminderSound plays the reminder sound when sounds are enabled. If no
+// sound player is available, it returns notify-send hints asking the
+// notification server to play the sound instead.
+func (nm *NotificationManager) playReminderSound(reminderType ReminderType) []string {
+	cfg := nm.store.Effective()
+	if !cfg.NotificationSound {
+		return nil
+	}
+
+	s := reminderSound(cfg, reminderType)
+	err := s.Play()
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sound.ErrNoPlayer) {
+		log.Printf("Failed to play notification sound: %v", err)
+	}
+	return s.NotificationHints()
+}
+
+// playTestSound plays a sound for the settings "Test sound" buttons, falling
+// back to a notification carrying the sound hint when no player is installed
+func playTestSound(s sound.Sound) error {
+	err := s.Play()
+	if !errors.Is(err, sound.ErrNoPlayer) {
+		return err
+	}
+
+	args := append([]string{"--app-name=MeetingBar"}, s.NotificationHints()...)
+	args = append(args, "MeetingBar", "Testing notification sound")
+	if !runNotifySend(args, nil) {
+		return fmt.Errorf("no sound player or notify-send available")
+	}
+	return nil
+}
+
+// tryNotifySend sends the notification with notify-send; meeting may be nil,
+// otherwise onJoin is called after its link is opened from the notification.
+// Quiet notifications use low urgency and are never persistent.
+func (nm *NotificationManager) tryNotifySend(title, message string, meeting *calendar.Meeting, hints []string, quiet bool, onJoin func()) bool {
+	cfg := nm.store.Effective()
+	persistent := cfg.PersistentNotifications && !quiet
+
+	urgency := "normal"
+	if quiet {
+		urgency = "low"
+	} else if persistent {
+		// GNOME Shell ignores the expire timeout and only keeps critical notifications on screen
+		urgency = "critical"
 	}
 
 	args := []string{
-		"notify-send",
 		"--app-name=MeetingBar",
 		"--category=calendar",
+		"--urgency=" + urgency,
+	}
+	if persistent {
+		args = append(args, "--expire-time=0")
+	}
+
+	args = append(args, hints...)
+
+	// Add action button if meeting has a link (GNOME/KDE support)
+	var onAction func(string)
+	if meeting != nil && meeting.MeetingLink != nil {
+		args = append(args, "--action="+actionJoin+"=Join Meeting")
+
+		joinMeeting := *meeting
+		onAction = func(action string) {
+			if action == actionJoin {
+				log.Printf("Joining meeting from notification: %s", joinMeeting.Title)
+				openMeetingLink(&joinMeeting)
+				onJoin()
+			}
+		}
+	}
+	args = append(args, title, message)
+
+	if !runNotifySend(args, onAction) {
+		return false
+	}
+
+	log.Printf("Sent notification: %s", title)
+	return true
+}
+
+// NotifyReauthRequired tells the user that an account has to be signed in again;
+// onSignIn is called when the notification's "Sign in again" action is chosen
+func (nm *NotificationManager) NotifyReauthRequired(account config.Account, reason string, onSignIn func()) {
+	title := "MeetingBar: sign in again"
+	message := fmt.Sprintf("%s\n%s. Meetings from this account are not shown until you sign in again.", account.Email, reason)
+
+	args := []string{
+		"--app-name=MeetingBar",
 		"--urgency=normal",
+		"--action=" + actionReauth + "=Sign in again",
 		title,
 		message,
 	}
+	sent := runNotifySend(args, func(action string) {
+		if action == actionReauth {
+			onSignIn()
+		}
+	})
+	if sent {
+		return
+	}
 
-	// Add action button if meeting has a link (GNOME/KDE support)
-	if meeting.MeetingLink != nil {
-		args = append(args, "--action=join=Join Meeting")
+	if err := beeep.Notify(title, message, ""); err != nil {
+		log.Printf("Failed to send notification: %v", err)
 	}
+}
 
-	cmd := exec.Command(args[0], args[1:]...)
-	err := cmd.Run()
-	
-	if err != nil {
+// notifySendStartupTimeout is how long runNotifySend waits for notify-send to
+// fail before it counts the notification as shown
+const notifySendStartupTimeout = 500 * time.Millisecond
+
+var (
+	notifySendActionsOnce     sync.Once
+	notifySendSupportsActions bool
+)
+
+// notifySendHasActions reports whether the installed notify-send takes
+// --action, which libnotify only added in 0.7.10; older ones reject it
+func notifySendHasActions() bool {
+	notifySendActionsOnce.Do(func() {
+		help, err := exec.Command("notify-send", "--help").Output()
+		notifySendSupportsActions = err == nil && bytes.Contains(help, []byte("--action"))
+		if !notifySendSupportsActions {
+			log.Printf("notify-send does not support actions, notifications are sent without buttons")
+		}
+	})
+	return notifySendSupportsActions
+}
+
+// runNotifySend starts notify-send with the given arguments. notify-send blocks
+// until the notification is closed and prints the chosen action, so it is
+// waited for in the background and onAction is called with the action, if any.
+// It returns false when notify-send is missing or fails right away, so the
+// caller can fall back to another way of notifying.
+func runNotifySend(args []string, onAction func(action string)) bool {
+	// Check if notify-send is available
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return false
+	}
+
+	if !notifySendHasActions() {
+		var withoutActions []string
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "--action=") {
+				withoutActions = append(withoutActions, arg)
+			}
+		}
+		args = withoutActions
+	}
+
+	cmd := exec.Command("notify-send", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
 		log.Printf("notify-send failed: %v", err)
 		return false
 	}
 
-	// If we added an action, we need to handle the response
-	// This is complex and varies by desktop environment
-	// For now, we'll just log that we sent the notification
-	log.Printf("Sent notification for meeting: %s", meeting.Title)
-	return true
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	handleExit := func(err error) bool {
+		if err != nil {
+			log.Printf("notify-send failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+			return false
+		}
+		if action := strings.TrimSpace(stdout.String()); action != "" && onAction != nil {
+			onAction(action)
+		}
+		return true
+	}
+
+	// notify-send exits at once when it rejects its arguments or cannot reach
+	// the notification server; otherwise it runs until the notification closes
+	select {
+	case err := <-done:
+		return handleExit(err)
+	case <-time.After(notifySendStartupTimeout):
+		go func() {
+			handleExit(<-done)
+		}()
+		return true
+	}
 }
 
-// StartNotificationWatcher starts a goroutine that periodically checks for upcoming meetings
+// openMeetingLink opens the meeting's join link in the default browser
+func openMeetingLink(meeting *calendar.Meeting) {
+	if meeting.MeetingLink == nil {
+		log.Printf("No meeting link found for: %s", meeting.Title)
+		return
+	}
+
+	if err := exec.Command("xdg-open", meeting.MeetingLink.URL).Start(); err != nil {
+		log.Printf("Failed to open meeting URL: %v", err)
+	}
+}
+
+// StartNotificationWatcher starts a goroutine that periodically checks for upcoming
+// meetings, and re-surfaces reminders missed while the screen was locked
 func (nm *NotificationManager) StartNotificationWatcher() {
 	ticker := time.NewTicker(1 * time.Minute) // Check every minute
 	
 	go func() {
 		defer ticker.Stop()
 		for range ticker.C {
+			nm.mu.Lock()
 			nm.checkForUpcomingMeetings()
+			nm.mu.Unlock()
 		}
 	}()
+
+	var lockedAt time.Time
+	err := watchScreenLock(func(locked bool) {
+		if locked {
+			lockedAt = time.Now()
+			return
+		}
+		if !lockedAt.IsZero() {
+			nm.resurfaceMissedReminders(lockedAt, time.Now())
+			lockedAt = time.Time{}
+		}
+	})
+	if err != nil {
+		log.Printf("Screen lock detection unavailable, missed reminders will not be re-surfaced: %v", err)
+	}
 }
 
 // ShowNotification sends a notification for a specific meeting (used for testing)
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"meetingbar/calendar"
+	"meetingbar/config"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// quietLevel says how a reminder is delivered during quiet hours or Do Not Disturb
+type quietLevel int
+
+const (
+	quietNone quietLevel = iota
+	quietSilent
+	quietSuppress
+)
+
+// quietLevelFor decides how to deliver a reminder about meeting, which may be nil
+func (nm *NotificationManager) quietLevelFor(meeting *calendar.Meeting, now time.Time) quietLevel {
+	cfg := nm.store.Effective()
+	if meeting != nil && matchesAlertRule(cfg.AlwaysAlert, meeting) {
+		return quietNone
+	}
+
+	quiet := inQuietHours(cfg.QuietHours, meeting, now)
+	if !quiet && cfg.RespectDoNotDisturb {
+		quiet = doNotDisturbActive()
+	}
+	if !quiet {
+		return quietNone
+	}
+
+	if cfg.QuietMode == config.QuietModeSuppress {
+		return quietSuppress
+	}
+	return quietSilent
+}
+
+// matchesAlertRule reports whether the meeting matches an "always alert" rule.
+// Rules with no fields set are ignored.
+func matchesAlertRule(rules []config.AlertRule, meeting *calendar.Meeting) bool {
+	for _, rule := range rules {
+		if rule.TitleContains == "" && rule.CalendarID == "" {
+			continue
+		}
+		if rule.TitleContains != "" && !strings.Contains(strings.ToLower(meeting.Title), strings.ToLower(rule.TitleContains)) {
+			continue
+		}
+		if rule.CalendarID != "" && rule.CalendarID != meeting.CalendarID {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// inQuietHours reports whether now falls in a quiet hours period that applies
+// to the meeting's calendar. Without a meeting only periods for all calendars apply.
+func inQuietHours(periods []config.QuietHours, meeting *calendar.Meeting, now time.Time) bool {
+	for _, period := range periods {
+		if len(period.Calendars) > 0 && (meeting == nil || !containsString(period.Calendars, meeting.CalendarID)) {
+			continue
+		}
+
+		active, err := period.Contains(now)
+		if err != nil {
+			log.Printf("Ignoring invalid quiet hours %s-%s: %v", period.Start, period.End, err)
+			continue
+		}
+		if active {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// doNotDisturbActive reports whether the desktop's Do Not Disturb mode is on
+func doNotDisturbActive() bool {
+	return gnomeBannersDisabled() || notificationsInhibited()
+}
+
+// gnomeBannersDisabled checks GNOME's Do Not Disturb switch, which turns off banners
+func gnomeBannersDisabled() bool {
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return false
+	}
+
+	// Fails when the GNOME schema is not installed
+	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.notifications", "show-banners").Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "false"
+}
+
+// notificationsInhibited reads the Inhibited property that KDE Plasma and other
+// notification servers expose while Do Not Disturb is on
+func notificationsInhibited() bool {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return false
+	}
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	variant, err := obj.GetProperty("org.freedesktop.Notifications.Inhibited")
+	if err != nil {
+		// Not every notification server implements the property
+		return false
+	}
+
+	inhibited, ok := variant.Value().(bool)
+	return ok && inhibited
+}
@@ -0,0 +1,39 @@
+//go:build gtk
+
+package ui
+
+import (
+	"log"
+	"runtime"
+
+	"meetingbar/calendar"
+	"meetingbar/ui/gtk"
+)
+
+// showInterruptiveReminder shows the full-screen GTK join prompt for a meeting
+// that has just started
+func (nm *NotificationManager) showInterruptiveReminder(meeting calendar.Meeting) {
+	details := gtk.ReminderDetails{
+		Title:     meeting.Title,
+		StartTime: meeting.StartTime,
+		EndTime:   meeting.EndTime,
+		Attendees: meeting.Attendees,
+		HasLink:   meeting.MeetingLink != nil,
+	}
+
+	// The window cannot carry a notification sound hint, so only a player is used
+	nm.playReminderSound(ReminderInterruptive)
+
+	// Run GTK in a separate goroutine with proper OS thread locking
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		action, err := gtk.ShowReminderWindowBlocking(details)
+		if err != nil {
+			log.Printf("GTK reminder window error: %v", err)
+			return
+		}
+		nm.handleReminderAction(meeting, ReminderInterruptive, string(action))
+	}()
+}
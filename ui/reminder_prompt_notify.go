@@ -0,0 +1,51 @@
+//go:build !gtk
+
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"meetingbar/calendar"
+
+	"github.com/gen2brain/beeep"
+)
+
+// showInterruptiveReminder falls back to a critical, persistent notification
+// with Join/Snooze/Dismiss actions when MeetingBar is built without GTK
+func (nm *NotificationManager) showInterruptiveReminder(meeting calendar.Meeting) {
+	title := "Meeting Starting: " + meeting.Title
+	message := fmt.Sprintf("%s - %s", meeting.StartTime.Format("15:04"), meeting.EndTime.Format("15:04"))
+	if len(meeting.Attendees) > 0 {
+		message += "\nAttendees: " + strings.Join(meeting.Attendees, ", ")
+	}
+
+	args := []string{
+		"--app-name=MeetingBar",
+		"--category=calendar",
+		"--urgency=critical",
+		"--expire-time=0",
+	}
+	args = append(args, nm.playReminderSound(ReminderInterruptive)...)
+	if meeting.MeetingLink != nil {
+		args = append(args, "--action="+actionJoin+"=Join Meeting")
+	}
+	args = append(args,
+		"--action="+actionSnooze+"=Snooze",
+		"--action="+actionDismiss+"=Dismiss",
+		title,
+		message,
+	)
+
+	if runNotifySend(args, func(action string) {
+		nm.handleReminderAction(meeting, ReminderInterruptive, action)
+	}) {
+		log.Printf("Sent interruptive reminder for meeting: %s", meeting.Title)
+		return
+	}
+
+	if err := beeep.Notify(title, message, ""); err != nil {
+		log.Printf("Failed to send notification: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// screenSaverInterfaces emit ActiveChanged(bool) when the screen locks or unlocks
+var screenSaverInterfaces = []string{
+	"org.freedesktop.ScreenSaver",
+	"org.gnome.ScreenSaver",
+}
+
+// watchScreenLock calls onChange with true when the screen locks and false when
+// it unlocks. Desktops that implement both interfaces report each change twice,
+// so repeated states are dropped.
+func watchScreenLock(onChange func(locked bool)) error {
+	// A private connection keeps our signal channel separate from other session bus users
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	for _, iface := range screenSaverInterfaces {
+		if err := conn.AddMatchSignal(
+			dbus.WithMatchInterface(iface),
+			dbus.WithMatchMember("ActiveChanged"),
+		); err != nil {
+			conn.Close()
+			return fmt.Errorf("failed to watch %s: %w", iface, err)
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+
+	go func() {
+		locked := false
+		for signal := range signals {
+			if len(signal.Body) != 1 {
+				continue
+			}
+			active, ok := signal.Body[0].(bool)
+			if !ok || active == locked {
+				continue
+			}
+			locked = active
+			onChange(locked)
+		}
+	}()
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os/exec"
+	"sort"
 	"strings"
 
 	"meetingbar/calendar"
@@ -14,20 +15,24 @@ import (
 )
 
 type SettingsManager struct {
-	config          *config.Config
+	store           *config.Store
+	config          *config.Config // the settings being edited
 	calendarService *calendar.UnifiedCalendarService
 	ctx             context.Context
 }
 
-func NewSettingsManager(cfg *config.Config, ctx context.Context) *SettingsManager {
+func NewSettingsManager(store *config.Store, ctx context.Context) *SettingsManager {
 	return &SettingsManager{
-		config:          cfg,
-		calendarService: calendar.NewUnifiedCalendarService(ctx, cfg),
+		store:           store,
+		config:          store.Get(),
+		calendarService: calendar.NewUnifiedCalendarService(ctx, store),
 		ctx:             ctx,
 	}
 }
 
 func (sm *SettingsManager) ShowSettings() error {
+	sm.config = sm.store.Get()
+	
 	// Check if zenity is available
 	if !sm.isZenityAvailable() {
 		log.Println("Zenity not found, using fallback settings display")
@@ -148,8 +153,10 @@ func (sm *SettingsManager) removeAccount(email string) error {
 	// Find and remove account
 	for i, account := range sm.config.Accounts {
 		if account.Email == email {
-			// Remove from keyring
-			config.DeleteToken(account.ID)
+			// Revoke access and remove from keyring
+			if err := calendar.RevokeAccount(sm.ctx, account.ID); err != nil {
+				log.Printf("Failed to revoke account %s: %v", account.Email, err)
+			}
 			
 			// Remove from config
 			sm.config.Accounts = append(sm.config.Accounts[:i], sm.config.Accounts[i+1:]...)
@@ -157,7 +164,7 @@ func (sm *SettingsManager) removeAccount(email string) error {
 		}
 	}
 	
-	return sm.config.Save()
+	return sm.store.Set(sm.config)
 }
 
 func (sm *SettingsManager) manageCalendars() error {
@@ -238,7 +245,7 @@ func (sm *SettingsManager) manageCalendars() error {
 		}
 	}
 	
-	return sm.config.Save()
+	return sm.store.Set(sm.config)
 }
 
 func (sm *SettingsManager) manageNotifications() error {
@@ -278,7 +285,7 @@ func (sm *SettingsManager) manageNotifications() error {
 		}
 	}
 	
-	return sm.config.Save()
+	return sm.store.Set(sm.config)
 }
 
 func (sm *SettingsManager) manageGeneral() error {
@@ -316,7 +323,7 @@ func (sm *SettingsManager) manageGeneral() error {
 		sm.config.RefreshInterval = 30
 	}
 	
-	return sm.config.Save()
+	return sm.store.Set(sm.config)
 }
 
 func (sm *SettingsManager) isZenityAvailable() bool {
@@ -342,6 +349,18 @@ func (sm *SettingsManager) showSimpleSettings() error {
 	fmt.Printf("Refresh Interval: %d minutes\n", sm.config.RefreshInterval)
 	fmt.Printf("Launch at Login: %t\n", sm.config.LaunchAtLogin)
 	
+	if readOnly := config.ReadOnlySettings(); len(readOnly) > 0 {
+		keys := make([]string, 0, len(readOnly))
+		for key := range readOnly {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Println("\nRead-only settings:")
+		for _, key := range keys {
+			fmt.Printf("  %s: %s\n", key, readOnly[key])
+		}
+	}
+	
 	fmt.Println("\n=== Configuration Help ===")
 	fmt.Println("To add a Google account:")
 	fmt.Println("1. Set up Google OAuth2 credentials (see README.md)")
@@ -349,7 +368,9 @@ func (sm *SettingsManager) showSimpleSettings() error {
 	fmt.Println("   export GOOGLE_CLIENT_ID=\"your-client-id\"")
 	fmt.Println("   export GOOGLE_CLIENT_SECRET=\"your-client-secret\"")
 	fmt.Println("3. Install zenity for GUI settings: sudo apt install zenity")
-	fmt.Println("\nConfig file location: ~/.config/meetingbar/config.json")
+	if configFile, err := config.ConfigFile(); err == nil {
+		fmt.Printf("\nConfig file location: %s\n", configFile)
+	}
 	fmt.Println("==========================\n")
 	
 	return nil
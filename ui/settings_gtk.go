@@ -15,9 +15,11 @@ type NativeSettingsManager struct {
 	gtkManager *gtk.GTKSettingsManager
 }
 
-func NewNativeSettingsManager(cfg *config.Config, ctx context.Context, onRefresh func()) *NativeSettingsManager {
+// NewNativeSettingsManager creates the GTK settings, which do not send
+// notifications and leave notificationMgr unused
+func NewNativeSettingsManager(store *config.Store, ctx context.Context, notificationMgr *NotificationManager) *NativeSettingsManager {
 	return &NativeSettingsManager{
-		gtkManager: gtk.NewGTKSettingsManager(cfg, ctx, onRefresh),
+		gtkManager: gtk.NewGTKSettingsManager(store, ctx),
 	}
 }
 
@@ -14,22 +14,26 @@ import (
 )
 
 type AdvancedSettingsManager struct {
-	config          *config.Config
+	store           *config.Store
+	config          *config.Config // the settings being edited
 	calendarService *calendar.UnifiedCalendarService
 	ctx             context.Context
 	scanner         *bufio.Scanner
 }
 
-func NewAdvancedSettingsManager(cfg *config.Config, ctx context.Context) *AdvancedSettingsManager {
+func NewAdvancedSettingsManager(store *config.Store, ctx context.Context) *AdvancedSettingsManager {
 	return &AdvancedSettingsManager{
-		config:          cfg,
-		calendarService: calendar.NewUnifiedCalendarService(ctx, cfg),
+		store:           store,
+		config:          store.Get(),
+		calendarService: calendar.NewUnifiedCalendarService(ctx, store),
 		ctx:             ctx,
 		scanner:         bufio.NewScanner(os.Stdin),
 	}
 }
 
 func (sm *AdvancedSettingsManager) ShowSettings() error {
+	sm.config = sm.store.Get()
+	
 	// Check if zenity is available for GUI
 	if sm.isZenityAvailable() {
 		return sm.showGUISettings()
@@ -140,7 +144,7 @@ func (sm *AdvancedSettingsManager) manageOAuth2Credentials() {
 	fmt.Println("3. Enable the Google Calendar API")
 	fmt.Println("4. Create OAuth 2.0 Client IDs:")
 	fmt.Println("   - Application type: Desktop application")
-	fmt.Println("   - Authorized redirect URIs: http://localhost:8080/callback")
+	fmt.Println("   - No redirect URI is needed (a random local port is used)")
 	fmt.Println()
 	
 	// Show current status
@@ -208,7 +212,7 @@ func (sm *AdvancedSettingsManager) setOAuth2Credentials() {
 	sm.config.OAuth2.ClientID = clientID
 	sm.config.OAuth2.ClientSecret = clientSecret
 	
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save credentials: %v\n", err)
 	} else {
 		fmt.Println("✅ OAuth2 credentials saved successfully!")
@@ -225,7 +229,7 @@ func (sm *AdvancedSettingsManager) clearOAuth2Credentials() {
 		sm.config.OAuth2.ClientID = ""
 		sm.config.OAuth2.ClientSecret = ""
 		
-		if err := sm.config.Save(); err != nil {
+		if err := sm.store.Set(sm.config); err != nil {
 			fmt.Printf("❌ Failed to clear credentials: %v\n", err)
 		} else {
 			fmt.Println("✅ OAuth2 credentials cleared successfully!")
@@ -288,18 +292,36 @@ func (sm *AdvancedSettingsManager) manageAccounts() {
 }
 
 func (sm *AdvancedSettingsManager) addGoogleAccount() {
-	fmt.Println("\n🔄 Starting OAuth2 flow...")
-	fmt.Println("This will open a browser window for authentication.")
-	fmt.Print("Continue? (Y/n): ")
+	fmt.Println("\nHow do you want to sign in?")
+	fmt.Println("1. Open a browser on this machine")
+	fmt.Println("2. Enter a code on another device (for SSH or headless sessions)")
+	fmt.Println("3. Cancel")
+	fmt.Print("\nYour choice [1]: ")
 	
+	choice := "1"
 	if sm.scanner.Scan() {
-		response := strings.ToLower(strings.TrimSpace(sm.scanner.Text()))
-		if response == "n" {
-			return
+		if text := strings.TrimSpace(sm.scanner.Text()); text != "" {
+			choice = text
 		}
 	}
 	
-	account, err := calendar.StartOAuth2Flow(sm.ctx, sm.config)
+	var account *config.Account
+	var err error
+	switch choice {
+	case "1":
+		fmt.Println("\n🔄 Starting OAuth2 flow...")
+		fmt.Println("This will open a browser window for authentication.")
+		account, err = calendar.StartOAuth2Flow(sm.ctx, sm.config)
+	case "2":
+		var flow *calendar.DeviceFlow
+		flow, err = calendar.StartDeviceFlow(sm.ctx, sm.config)
+		if err == nil {
+			fmt.Printf("Waiting for approval (the code expires at %s)...\n", flow.ExpiresAt.Local().Format("15:04"))
+			account, err = flow.Wait(sm.ctx)
+		}
+	default:
+		return
+	}
 	if err != nil {
 		fmt.Printf("❌ Failed to add account: %v\n", err)
 		return
@@ -307,12 +329,15 @@ func (sm *AdvancedSettingsManager) addGoogleAccount() {
 	
 	// Add to config
 	sm.config.Accounts = append(sm.config.Accounts, *account)
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save account: %v\n", err)
 		return
 	}
 	
 	fmt.Printf("✅ Successfully added account: %s\n", account.Email)
+	if warning := calendar.MissingScopesWarning(account); warning != "" {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
 }
 
 func (sm *AdvancedSettingsManager) removeGoogleAccount() {
@@ -342,13 +367,16 @@ func (sm *AdvancedSettingsManager) removeGoogleAccount() {
 	
 	if sm.scanner.Scan() {
 		if strings.ToLower(strings.TrimSpace(sm.scanner.Text())) == "y" {
-			// Remove from keyring
-			config.DeleteToken(account.ID)
+			// Revoke access and remove from keyring
+			if err := calendar.RevokeAccount(sm.ctx, account.ID); err != nil {
+				fmt.Printf("⚠️  Could not revoke access at Google: %v\n", err)
+				fmt.Println("   You can remove MeetingBar at https://myaccount.google.com/permissions")
+			}
 			
 			// Remove from config
 			sm.config.Accounts = append(sm.config.Accounts[:num-1], sm.config.Accounts[num:]...)
 			
-			if err := sm.config.Save(); err != nil {
+			if err := sm.store.Set(sm.config); err != nil {
 				fmt.Printf("❌ Failed to save changes: %v\n", err)
 			} else {
 				fmt.Printf("✅ Account %s removed successfully!\n", account.Email)
@@ -474,7 +502,7 @@ func (sm *AdvancedSettingsManager) toggleCalendar(calendars []config.Calendar) {
 		sm.config.EnabledCalendars = append(sm.config.EnabledCalendars, cal.ID)
 	}
 	
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save changes: %v\n", err)
 	} else {
 		status := "enabled"
@@ -491,7 +519,7 @@ func (sm *AdvancedSettingsManager) enableAllCalendars(calendars []config.Calenda
 		sm.config.EnabledCalendars = append(sm.config.EnabledCalendars, cal.ID)
 	}
 	
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save changes: %v\n", err)
 	} else {
 		fmt.Printf("✅ All %d calendars enabled!\n", len(calendars))
@@ -501,7 +529,7 @@ func (sm *AdvancedSettingsManager) enableAllCalendars(calendars []config.Calenda
 func (sm *AdvancedSettingsManager) disableAllCalendars() {
 	sm.config.EnabledCalendars = nil
 	
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save changes: %v\n", err)
 	} else {
 		fmt.Println("✅ All calendars disabled!")
@@ -538,14 +566,14 @@ func (sm *AdvancedSettingsManager) manageNotifications() {
 	switch choice {
 	case "1":
 		sm.config.EnableNotifications = true
-		if err := sm.config.Save(); err != nil {
+		if err := sm.store.Set(sm.config); err != nil {
 			fmt.Printf("❌ Failed to save: %v\n", err)
 		} else {
 			fmt.Println("✅ Notifications enabled!")
 		}
 	case "2":
 		sm.config.EnableNotifications = false
-		if err := sm.config.Save(); err != nil {
+		if err := sm.store.Set(sm.config); err != nil {
 			fmt.Printf("❌ Failed to save: %v\n", err)
 		} else {
 			fmt.Println("✅ Notifications disabled!")
@@ -586,7 +614,7 @@ func (sm *AdvancedSettingsManager) changeNotificationTiming() {
 	}
 	
 	sm.config.NotificationTime = options[choice-1]
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save: %v\n", err)
 	} else {
 		fmt.Printf("✅ Notification timing set to %d minutes before meeting!\n", sm.config.NotificationTime)
@@ -617,7 +645,7 @@ func (sm *AdvancedSettingsManager) manageGeneral() {
 	switch choice {
 	case "1":
 		sm.config.LaunchAtLogin = !sm.config.LaunchAtLogin
-		if err := sm.config.Save(); err != nil {
+		if err := sm.store.Set(sm.config); err != nil {
 			fmt.Printf("❌ Failed to save: %v\n", err)
 		} else {
 			status := "enabled"
@@ -660,7 +688,7 @@ func (sm *AdvancedSettingsManager) changeRefreshInterval() {
 	}
 	
 	sm.config.RefreshInterval = options[choice-1]
-	if err := sm.config.Save(); err != nil {
+	if err := sm.store.Set(sm.config); err != nil {
 		fmt.Printf("❌ Failed to save: %v\n", err)
 	} else {
 		fmt.Printf("✅ Refresh interval set to %d minutes!\n", sm.config.RefreshInterval)
@@ -718,8 +746,12 @@ func (sm *AdvancedSettingsManager) showCurrentConfig() {
 	
 	// File locations
 	fmt.Printf("\n📁 File Locations:\n")
-	fmt.Println("   Config: ~/.config/meetingbar/config.json")
-	fmt.Println("   Cache: ~/.cache/meetingbar/")
+	if configFile, err := config.ConfigFile(); err == nil {
+		fmt.Printf("   Config: %s\n", configFile)
+	}
+	if stateDir, err := config.GetStateDir(); err == nil {
+		fmt.Printf("   Logs and reminder history: %s\n", stateDir)
+	}
 	fmt.Println("   Credentials: System keyring")
 	
 	fmt.Print("\nPress Enter to continue...")
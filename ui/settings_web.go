@@ -13,9 +13,9 @@ type NativeSettingsManager struct {
 	webManager *WebSettingsManager
 }
 
-func NewNativeSettingsManager(cfg *config.Config, ctx context.Context, onRefresh func()) *NativeSettingsManager {
+func NewNativeSettingsManager(store *config.Store, ctx context.Context, notificationMgr *NotificationManager) *NativeSettingsManager {
 	return &NativeSettingsManager{
-		webManager: NewWebSettingsManager(cfg, ctx),
+		webManager: NewWebSettingsManager(store, ctx, notificationMgr),
 	}
 }
 
@@ -0,0 +1,119 @@
+package sound
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Sound names from the freedesktop sound theme specification
+const (
+	ThemeMessageNewInstant = "message-new-instant"
+	ThemeAlarmClockElapsed = "alarm-clock-elapsed"
+)
+
+// ErrNoPlayer is returned when no sound player is installed, or when a theme
+// sound cannot be resolved to a file for players that need one
+var ErrNoPlayer = errors.New("no sound player available")
+
+// Sound is a freedesktop theme sound, optionally overridden by a custom file
+type Sound struct {
+	Name string
+	File string
+}
+
+// Play starts playing the sound in the background using canberra-gtk-play,
+// pw-play or paplay, whichever is installed first
+func (s Sound) Play() error {
+	customFile := s.customFile()
+
+	if _, err := exec.LookPath("canberra-gtk-play"); err == nil {
+		args := []string{"--description=MeetingBar", "-i", s.Name}
+		if customFile != "" {
+			args = []string{"--description=MeetingBar", "-f", customFile}
+		}
+		return start(exec.Command("canberra-gtk-play", args...))
+	}
+
+	// pw-play and paplay only play files, so resolve theme sounds first
+	file := customFile
+	if file == "" {
+		file = findThemeSound(s.Name)
+	}
+	if file == "" {
+		return ErrNoPlayer
+	}
+
+	for _, player := range []string{"pw-play", "paplay"} {
+		if _, err := exec.LookPath(player); err == nil {
+			return start(exec.Command(player, file))
+		}
+	}
+
+	return ErrNoPlayer
+}
+
+// NotificationHints returns notify-send arguments asking the notification
+// server to play the sound itself
+func (s Sound) NotificationHints() []string {
+	if customFile := s.customFile(); customFile != "" {
+		return []string{"--hint=string:sound-file:" + customFile}
+	}
+	return []string{"--hint=string:sound-name:" + s.Name}
+}
+
+// customFile returns the custom sound file, or "" to fall back to the theme
+// sound when none is set or the file has gone missing
+func (s Sound) customFile() string {
+	if s.File == "" {
+		return ""
+	}
+	if _, err := os.Stat(s.File); err != nil {
+		log.Printf("Custom sound file unavailable, using %s instead: %v", s.Name, err)
+		return ""
+	}
+	return s.File
+}
+
+func start(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", filepath.Base(cmd.Path), err)
+	}
+
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			log.Printf("Sound player %s failed: %v", filepath.Base(cmd.Path), err)
+		}
+	}()
+	return nil
+}
+
+// findThemeSound looks the sound up in the freedesktop theme directories
+func findThemeSound(name string) string {
+	var dataDirs []string
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		dataDirs = append(dataDirs, dataHome)
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
+		dataDirs = append(dataDirs, filepath.Join(homeDir, ".local", "share"))
+	}
+
+	systemDirs := os.Getenv("XDG_DATA_DIRS")
+	if systemDirs == "" {
+		systemDirs = "/usr/local/share:/usr/share"
+	}
+	dataDirs = append(dataDirs, strings.Split(systemDirs, ":")...)
+
+	for _, dir := range dataDirs {
+		for _, ext := range []string{".oga", ".ogg", ".wav"} {
+			path := filepath.Join(dir, "sounds", "freedesktop", "stereo", name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
@@ -2,11 +2,14 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os/exec"
+	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"meetingbar/calendar"
@@ -16,10 +19,21 @@ import (
 )
 
 type TrayManager struct {
-	config          *config.Config
+	store           *config.Store
 	calendarService *calendar.UnifiedCalendarService
 	meetings        []calendar.Meeting
+	lastSnapshot    []calendar.Meeting // last successful fetch, nil until the first one
+	lastFetched     map[string]bool    // calendars read by that fetch, see calendarKey
+	lastScope       string             // what that fetch covered, see refreshScope
 	ticker          *time.Ticker
+	
+	// mu guards the fields shared with the menu, settings and notification
+	// goroutines; refreshMu lets one refresh run at a time
+	mu              sync.Mutex
+	refreshMu       sync.Mutex
+	
+	// Settings the ticker and the menu were last set up with
+	applied         *config.Config
 	ctx             context.Context
 	cancel          context.CancelFunc
 	notificationMgr *NotificationManager
@@ -34,34 +48,89 @@ type TrayManager struct {
 	createItem        *systray.MenuItem
 	rateItem          *systray.MenuItem
 	quickActionsHeader *systray.MenuItem
+	recentItem        *systray.MenuItem
+	deviceCodeItem    *systray.MenuItem
+	deviceCodeURL     string // guarded by mu
+	
+	// Pre-allocated "Sign in again" items and the accounts they are shown for
+	reauthSlots       []*systray.MenuItem
+	reauthAccounts    []config.Account
+	oauthSessions     *calendar.OAuthSessionManager
+	
+	// Takes the commands of later launches; nil when the session bus is unavailable
+	instance          *Instance
 	
 	// Pre-allocated meeting items to maintain order
 	maxMeetingSlots   int
 	meetingSlots      []*systray.MenuItem
+	
+	// Pre-allocated "Recent reminders" submenu items and the entries they show
+	recentSlots       []*systray.MenuItem
+	recentEntries     []HistoryEntry
+	
+	// "Profile" submenu items, the profiles they show and the profile in
+	// effect when they were last updated
+	profileItem       *systray.MenuItem
+	profileAutoItem   *systray.MenuItem
+	profileSlots      []*systray.MenuItem
+	profileNames      []string
+	currentProfile    string
 }
 
+// maxRecentReminders is the number of reminders listed in the tray submenu
+const maxRecentReminders = 8
+
+// maxReauthSlots is the number of accounts that can be offered for signing in again
+const maxReauthSlots = 4
+
+// maxProfileSlots is the number of profiles that can be chosen from the tray submenu
+const maxProfileSlots = 8
+
+// profileCheckInterval is how often the profile schedule is checked for a switch
+const profileCheckInterval = time.Minute
+
 var trayManager *TrayManager
 
-func OnReady(cfg *config.Config) {
+// OnReady sets up the tray, runs command and then the commands that other
+// launches forward through instance, which is nil when the bus is unavailable
+func OnReady(store *config.Store, instance *Instance, command Command) {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	trayManager = &TrayManager{
-		config:          cfg,
-		calendarService: calendar.NewUnifiedCalendarService(ctx, cfg),
+		store:           store,
+		calendarService: calendar.NewUnifiedCalendarService(ctx, store),
+		applied:         store.Get(),
 		ctx:             ctx,
 		cancel:          cancel,
-		notificationMgr: NewNotificationManager(cfg),
+		notificationMgr: NewNotificationManager(store),
+		oauthSessions:   calendar.NewOAuthSessionManager(),
+		instance:        instance,
 	}
 	
-	// Set up settings manager with refresh callback
-	trayManager.settingsMgr = NewNativeSettingsManager(cfg, ctx, func() {
-		trayManager.refreshMeetings()
-	})
+	// Settings changes are applied through the store subscription
+	trayManager.settingsMgr = NewNativeSettingsManager(store, ctx, trayManager.notificationMgr)
+	
+	// Tokens left in the other secret store, e.g. after the keyring became unavailable, are moved first
+	config.MigrateSecrets(store.Get())
 	
 	trayManager.setupTray()
+	trayManager.notificationMgr.SetHistoryChangedCallback(trayManager.updateRecentReminders)
+	trayManager.updateRecentReminders()
 	trayManager.startPeriodicRefresh()
+	trayManager.watchProfileSchedule()
+	store.Subscribe(trayManager.applySettings)
 	trayManager.notificationMgr.StartNotificationWatcher()
 	trayManager.refreshMeetings()
+	NewAutostart(store).Start(ctx)
+	
+	if err := config.WatchConfig(ctx, store); err != nil {
+		log.Printf("Config changes on disk will not be applied until restart: %v", err)
+	}
+	
+	if err := trayManager.runCommand(command); err != nil {
+		log.Printf("Failed to run command %q: %v", command, err)
+	}
+	instance.serve(trayManager.runCommand)
 }
 
 func OnExit() {
@@ -90,6 +159,21 @@ func (tm *TrayManager) setupMenuStructure() {
 	tm.titleItem = systray.AddMenuItem(dateHeader, "Today's meetings")
 	tm.titleItem.Disable()
 	
+	// Shown while an account is being added with a device code
+	tm.deviceCodeItem = systray.AddMenuItem("", "")
+	tm.deviceCodeItem.Hide()
+	go tm.handleDeviceCodeClick()
+	
+	// Shown for accounts whose credentials stopped working
+	tm.reauthSlots = make([]*systray.MenuItem, maxReauthSlots)
+	for i := range tm.reauthSlots {
+		item := systray.AddMenuItem("", "")
+		item.Hide()
+		tm.reauthSlots[i] = item
+		go tm.handleReauthClick(i)
+	}
+	tm.updateReauthItems(tm.applied.Accounts)
+	
 	systray.AddSeparator()
 	
 	// Pre-create meeting slots to maintain proper order
@@ -109,6 +193,28 @@ func (tm *TrayManager) setupMenuStructure() {
 	
 	tm.createItem = systray.AddMenuItem("➕ Create meeting", "Create a new meeting")
 	tm.refreshItem = systray.AddMenuItem("🔄 Refresh", "Refresh calendar data")
+	
+	tm.recentItem = systray.AddMenuItem("🕘 Recent reminders", "Reminders shown recently")
+	tm.recentSlots = make([]*systray.MenuItem, maxRecentReminders)
+	for i := range tm.recentSlots {
+		item := tm.recentItem.AddSubMenuItem("", "")
+		item.Hide()
+		tm.recentSlots[i] = item
+		go tm.handleRecentReminderClick(i)
+	}
+	
+	tm.profileItem = systray.AddMenuItem("👤 Profile", "Switch between sets of settings")
+	tm.profileAutoItem = tm.profileItem.AddSubMenuItemCheckbox("Automatic", "Switch profiles by schedule", false)
+	go tm.handleProfileClick(tm.profileAutoItem, -1)
+	tm.profileSlots = make([]*systray.MenuItem, maxProfileSlots)
+	for i := range tm.profileSlots {
+		item := tm.profileItem.AddSubMenuItemCheckbox("", "", false)
+		item.Hide()
+		tm.profileSlots[i] = item
+		go tm.handleProfileClick(item, i)
+	}
+	tm.updateProfileItems(tm.applied)
+	
 	tm.settingsItem = systray.AddMenuItem("⚙️ Settings", "Open settings")
 	tm.rateItem = systray.AddMenuItem("⭐ Rate MeetingBar", "Help us improve by rating the app")
 	
@@ -166,7 +272,7 @@ func (tm *TrayManager) handleMenuClicks() {
 }
 
 func (tm *TrayManager) startPeriodicRefresh() {
-	tm.ticker = time.NewTicker(tm.config.GetRefreshDuration())
+	tm.ticker = time.NewTicker(tm.applied.GetRefreshDuration())
 	
 	go func() {
 		for {
@@ -181,13 +287,17 @@ func (tm *TrayManager) startPeriodicRefresh() {
 }
 
 func (tm *TrayManager) refreshMeetings() {
+	tm.refreshMu.Lock()
+	defer tm.refreshMu.Unlock()
+	
+	cfg := tm.store.Effective()
 	log.Printf("refreshMeetings: backend=%s, requiresAuth=%t, accountCount=%d", 
-		tm.config.CalendarBackend, 
+		cfg.CalendarBackend, 
 		tm.calendarService.RequiresAuthentication(), 
-		len(tm.config.Accounts))
+		len(cfg.Accounts))
 		
 	// Check backend requirements - only show no accounts for Google backend
-	if tm.calendarService.RequiresAuthentication() && len(tm.config.Accounts) == 0 {
+	if tm.calendarService.RequiresAuthentication() && len(cfg.Accounts) == 0 {
 		log.Printf("No accounts configured for backend that requires authentication")
 		tm.updateTrayForNoAccounts()
 		return
@@ -205,18 +315,19 @@ func (tm *TrayManager) refreshMeetings() {
 	}
 	
 	var allMeetings []calendar.Meeting
+	fetched := make(map[string]bool)
 	
 	if tm.calendarService.IsGnomeBackend() {
 		// For GNOME backend, we don't use accounts - get meetings directly
 		var enabledCalendars []string
-		if len(tm.config.EnabledCalendars) == 0 {
+		if len(cfg.EnabledCalendars) == 0 {
 			// Get all available calendars if none specifically enabled
 			calendars, err := tm.calendarService.GetCalendars("")
 			if err != nil {
 				log.Printf("Failed to get GNOME calendars: %v", err)
 				// For GNOME backend, show error as no meetings instead of no accounts
 				tm.updateTrayForNoMeetings()
-				tm.meetings = []calendar.Meeting{}
+				tm.setMeetings(nil)
 				tm.updateTrayDisplay()
 				return
 			}
@@ -226,59 +337,128 @@ func (tm *TrayManager) refreshMeetings() {
 				}
 			}
 		} else {
-			enabledCalendars = tm.config.EnabledCalendars
+			enabledCalendars = cfg.EnabledCalendars
 		}
 		
-		meetings, err := tm.calendarService.GetMeetings("", enabledCalendars)
+		meetings, failedCalendars, err := tm.calendarService.GetMeetings("", enabledCalendars)
 		if err != nil {
 			log.Printf("Failed to get meetings from GNOME Calendar: %v", err)
 			// For GNOME backend, show error as no meetings instead of no accounts
 			tm.updateTrayForNoMeetings()
-			tm.meetings = []calendar.Meeting{}
+			tm.setMeetings(nil)
 			tm.updateTrayDisplay()
 			return
 		}
 		allMeetings = meetings
+		markFetched(fetched, "", enabledCalendars, failedCalendars)
 	} else {
 		// For Google backend, iterate through accounts
-		for _, account := range tm.config.Accounts {
+		for _, account := range cfg.Accounts {
 			// Get enabled calendars for this account
 			var enabledCalendars []string
 			
 			// If no calendars are specifically enabled, try to get all calendars
-			if len(tm.config.EnabledCalendars) == 0 {
+			if len(cfg.EnabledCalendars) == 0 {
 				calendars, err := tm.calendarService.GetCalendars(account.ID)
 				if err != nil {
-					log.Printf("OAUTH ERROR - Failed to get calendars for account %s: %v", account.Email, err)
-					// This suggests credentials are lost - try to handle gracefully
-					tm.updateTrayForNoAccounts()
-					return
+					log.Printf("Failed to get calendars for account %s: %v", account.Email, err)
+					tm.handleAccountError(account, err)
+					continue
 				}
 				for _, cal := range calendars {
 					enabledCalendars = append(enabledCalendars, cal.ID)
 				}
 			} else {
-				enabledCalendars = tm.config.EnabledCalendars
+				enabledCalendars = cfg.EnabledCalendars
 			}
 			
-			meetings, err := tm.calendarService.GetMeetings(account.ID, enabledCalendars)
+			meetings, failedCalendars, err := tm.calendarService.GetMeetings(account.ID, enabledCalendars)
 			if err != nil {
 				log.Printf("Failed to get meetings for account %s: %v", account.Email, err)
+				tm.handleAccountError(account, err)
 				continue
 			}
 			
+			tm.setAccountAuthError(account.ID, nil)
 			allMeetings = append(allMeetings, meetings...)
+			markFetched(fetched, account.ID, enabledCalendars, failedCalendars)
 		}
 	}
 	
+	// Announce changes since the previous fetch. The first fetch after startup,
+	// or after the accounts, calendars or profile changed, only records a baseline.
+	now := time.Now()
+	scope := refreshScope(cfg, now)
+	if tm.lastSnapshot != nil && scope == tm.lastScope {
+		changes := diffMeetings(tm.lastSnapshot, allMeetings, tm.lastFetched, fetched, now)
+		tm.notificationMgr.NotifyMeetingChanges(changes)
+	}
+	
+	// Meetings marked cancelled were only needed to announce the cancellation
+	scheduled := allMeetings[:0]
+	for _, meeting := range allMeetings {
+		if !meeting.Cancelled {
+			scheduled = append(scheduled, meeting)
+		}
+	}
+	allMeetings = scheduled
+	
 	// Sort meetings by start time
 	sort.Slice(allMeetings, func(i, j int) bool {
 		return allMeetings[i].StartTime.Before(allMeetings[j].StartTime)
 	})
 	
-	tm.meetings = allMeetings
+	tm.lastSnapshot = append([]calendar.Meeting{}, allMeetings...)
+	tm.lastFetched = fetched
+	tm.lastScope = scope
+	
+	tm.setMeetings(allMeetings)
 	tm.notificationMgr.UpdateMeetings(allMeetings)
 	tm.updateTrayDisplay()
+	tm.updateRecentReminders()
+}
+
+// markFetched records the calendars of an account that a refresh read
+func markFetched(fetched map[string]bool, accountID string, calendarIDs, failedCalendars []string) {
+	failed := make(map[string]bool, len(failedCalendars))
+	for _, id := range failedCalendars {
+		failed[id] = true
+	}
+	for _, id := range calendarIDs {
+		if !failed[id] {
+			fetched[calendarKey(accountID, id)] = true
+		}
+	}
+}
+
+// refreshScope describes what a refresh covers: the backend, the accounts,
+// the enabled calendars and the profile in effect
+func refreshScope(cfg *config.Config, now time.Time) string {
+	var accountIDs []string
+	for _, account := range cfg.Accounts {
+		accountIDs = append(accountIDs, account.ID)
+	}
+	calendars := append([]string{}, cfg.EnabledCalendars...)
+	sort.Strings(accountIDs)
+	sort.Strings(calendars)
+	return fmt.Sprintf("%s;%s;%s;%s", cfg.CalendarBackend,
+		strings.Join(accountIDs, ","),
+		strings.Join(calendars, ","),
+		cfg.CurrentProfile(now))
+}
+
+// setMeetings replaces the meetings shown in the tray
+func (tm *TrayManager) setMeetings(meetings []calendar.Meeting) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.meetings = meetings
+}
+
+// findMeeting looks up a meeting shown in the tray
+func (tm *TrayManager) findMeeting(id string, startTime time.Time) *calendar.Meeting {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return findMeeting(tm.meetings, id, startTime)
 }
 
 func (tm *TrayManager) updateTrayDisplay() {
@@ -296,17 +476,7 @@ func (tm *TrayManager) updateTrayDisplay() {
 	}
 	
 	// Find current and upcoming meetings
-	var currentMeeting *calendar.Meeting
-	var upcomingMeetings []calendar.Meeting
-	
-	for i := range tm.meetings {
-		meeting := &tm.meetings[i]
-		if now.After(meeting.StartTime) && now.Before(meeting.EndTime) {
-			currentMeeting = meeting
-		} else if now.Before(meeting.StartTime) {
-			upcomingMeetings = append(upcomingMeetings, *meeting)
-		}
-	}
+	currentMeeting, upcomingMeetings := splitMeetings(tm.meetings, now)
 	
 	// Update tray title and tooltip
 	if currentMeeting != nil {
@@ -321,6 +491,24 @@ func (tm *TrayManager) updateTrayDisplay() {
 	tm.displayMeetingsInSlots(currentMeeting, upcomingMeetings, now)
 }
 
+// splitMeetings returns the meeting in progress (the most recently started one
+// when meetings overlap) and the meetings that have not started yet
+func splitMeetings(meetings []calendar.Meeting, now time.Time) (*calendar.Meeting, []calendar.Meeting) {
+	var currentMeeting *calendar.Meeting
+	var upcomingMeetings []calendar.Meeting
+	
+	for i := range meetings {
+		meeting := &meetings[i]
+		if now.After(meeting.StartTime) && now.Before(meeting.EndTime) {
+			currentMeeting = meeting
+		} else if now.Before(meeting.StartTime) {
+			upcomingMeetings = append(upcomingMeetings, *meeting)
+		}
+	}
+	
+	return currentMeeting, upcomingMeetings
+}
+
 func (tm *TrayManager) displayNoMeetingsInSlots() {
 	// Use first slot to show no meetings message
 	if len(tm.meetingSlots) > 0 {
@@ -376,7 +564,7 @@ func (tm *TrayManager) displayMeetingsInSlots(currentMeeting *calendar.Meeting,
 	}
 	
 	// Display upcoming meetings
-	maxMeetings := tm.config.MaxMeetings
+	maxMeetings := tm.store.Effective().MaxMeetings
 	if maxMeetings <= 0 {
 		maxMeetings = 5
 	}
@@ -464,17 +652,385 @@ func (tm *TrayManager) handleMeetingSlotClick(slot *systray.MenuItem, meeting *c
 
 
 
-func (tm *TrayManager) joinMeeting(meeting *calendar.Meeting) {
-	if meeting.MeetingLink == nil {
-		log.Printf("No meeting link found for: %s", meeting.Title)
+// updateRecentReminders lists the latest reminders in the "Recent reminders" submenu.
+// Entries for meetings that can still be joined are clickable.
+func (tm *TrayManager) updateRecentReminders() {
+	if tm.recentItem == nil {
+		return
+	}
+	
+	entries := tm.notificationMgr.RecentReminders(maxRecentReminders)
+	tm.mu.Lock()
+	tm.recentEntries = entries
+	tm.mu.Unlock()
+	
+	if len(entries) == 0 {
+		tm.recentItem.Disable()
+	} else {
+		tm.recentItem.Enable()
+	}
+	
+	now := time.Now()
+	for i, slot := range tm.recentSlots {
+		if i >= len(entries) {
+			slot.Hide()
+			continue
+		}
+		
+		entry := entries[i]
+		slot.SetTitle(fmt.Sprintf("%s  %s · %s (%s)",
+			entry.SentAt.Format("15:04"),
+			historyTypeLabel(entry.Type),
+			tm.truncateTitle(entry.MeetingTitle),
+			historyActionLabel(entry.Action)))
+		slot.SetTooltip(fmt.Sprintf("%s\n⏰ %s - %s",
+			entry.MeetingTitle,
+			entry.StartTime.Format("15:04"),
+			entry.EndTime.Format("15:04")))
+		
+		if meeting := tm.findMeeting(entry.MeetingID, entry.StartTime); meeting != nil && meeting.MeetingLink != nil && now.Before(meeting.EndTime) {
+			slot.Enable()
+		} else {
+			slot.Disable()
+		}
+		slot.Show()
+	}
+}
+
+func (tm *TrayManager) handleRecentReminderClick(index int) {
+	for {
+		select {
+		case <-tm.recentSlots[index].ClickedCh:
+			tm.mu.Lock()
+			var entry *HistoryEntry
+			if index < len(tm.recentEntries) {
+				entry = &tm.recentEntries[index]
+			}
+			tm.mu.Unlock()
+			
+			if entry != nil {
+				if meeting := tm.findMeeting(entry.MeetingID, entry.StartTime); meeting != nil {
+					tm.joinMeeting(meeting)
+				}
+			}
+		case <-tm.ctx.Done():
+			return
+		}
+	}
+}
+
+// updateProfileItems shows the profiles of cfg in the "Profile" submenu and
+// checks the one chosen. The submenu is hidden while there are no profiles.
+func (tm *TrayManager) updateProfileItems(cfg *config.Config) {
+	if tm.profileItem == nil {
+		return
+	}
+	
+	current := cfg.CurrentProfile(time.Now())
+	names := make([]string, 0, len(cfg.Profiles))
+	for _, profile := range cfg.Profiles {
+		names = append(names, profile.Name)
+	}
+	if len(names) > len(tm.profileSlots) {
+		log.Printf("Only the first %d profiles can be chosen from the tray", len(tm.profileSlots))
+		names = names[:len(tm.profileSlots)]
+	}
+	tm.mu.Lock()
+	tm.profileNames = names
+	tm.currentProfile = current
+	tm.mu.Unlock()
+	
+	if len(names) == 0 {
+		tm.profileItem.Hide()
+		return
+	}
+	
+	if current == "" {
+		tm.profileItem.SetTitle("👤 Profile: none")
+	} else {
+		tm.profileItem.SetTitle(fmt.Sprintf("👤 Profile: %s", current))
+	}
+	tm.profileItem.Show()
+	
+	if cfg.ActiveProfile == "" && current != "" {
+		tm.profileAutoItem.SetTitle(fmt.Sprintf("Automatic (%s)", current))
+	} else {
+		tm.profileAutoItem.SetTitle("Automatic")
+	}
+	setChecked(tm.profileAutoItem, cfg.ActiveProfile == "")
+	
+	for i, slot := range tm.profileSlots {
+		if i >= len(names) {
+			slot.Hide()
+			continue
+		}
+		slot.SetTitle(names[i])
+		slot.SetTooltip(fmt.Sprintf("Use the %s profile until another one is chosen", names[i]))
+		setChecked(slot, cfg.ActiveProfile == names[i])
+		slot.Show()
+	}
+}
+
+func setChecked(item *systray.MenuItem, checked bool) {
+	if checked {
+		item.Check()
+	} else {
+		item.Uncheck()
+	}
+}
+
+// handleProfileClick switches to the profile shown in the slot at index, or
+// back to switching by schedule for index -1
+func (tm *TrayManager) handleProfileClick(item *systray.MenuItem, index int) {
+	for {
+		select {
+		case <-item.ClickedCh:
+			name := ""
+			if index >= 0 {
+				tm.mu.Lock()
+				if index < len(tm.profileNames) {
+					name = tm.profileNames[index]
+				}
+				tm.mu.Unlock()
+				if name == "" {
+					continue
+				}
+			}
+			go tm.selectProfile(name)
+		case <-tm.ctx.Done():
+			return
+		}
+	}
+}
+
+// selectProfile saves the chosen profile; the store subscription then updates
+// the menu and refreshes the meetings
+func (tm *TrayManager) selectProfile(name string) {
+	err := tm.store.Update(func(cfg *config.Config) error {
+		return cfg.SelectProfile(name)
+	})
+	if err != nil {
+		log.Printf("Failed to switch profile: %v", err)
+	}
+}
+
+// watchProfileSchedule applies a profile switch made by the profile schedule,
+// e.g. when the weekend starts
+func (tm *TrayManager) watchProfileSchedule() {
+	ticker := time.NewTicker(profileCheckInterval)
+	
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cfg := tm.store.Get()
+				current := cfg.CurrentProfile(time.Now())
+				tm.mu.Lock()
+				changed := current != tm.currentProfile
+				tm.mu.Unlock()
+				
+				if changed {
+					log.Printf("Profile schedule switched to %q", current)
+					tm.updateProfileItems(cfg)
+					tm.refreshMeetings()
+				}
+			case <-tm.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// showDeviceCodeInTray shows the code for a pending device sign-in in the tray menu
+func showDeviceCodeInTray(userCode, verificationURL string, expiresAt time.Time) {
+	if trayManager == nil || trayManager.deviceCodeItem == nil {
+		return
+	}
+	
+	trayManager.mu.Lock()
+	trayManager.deviceCodeURL = verificationURL
+	trayManager.mu.Unlock()
+	trayManager.deviceCodeItem.SetTitle(fmt.Sprintf("🔑 Enter code %s at %s", userCode, verificationURL))
+	trayManager.deviceCodeItem.SetTooltip(fmt.Sprintf("Sign in to Google on any device to add the account. The code expires at %s.",
+		expiresAt.Local().Format("15:04")))
+	trayManager.deviceCodeItem.Show()
+}
+
+// clearDeviceCodeInTray hides the device sign-in code once the flow has finished
+func clearDeviceCodeInTray() {
+	if trayManager == nil || trayManager.deviceCodeItem == nil {
 		return
 	}
 	
-	// Open meeting URL in default browser
-	err := exec.Command("xdg-open", meeting.MeetingLink.URL).Start()
+	trayManager.mu.Lock()
+	trayManager.deviceCodeURL = ""
+	trayManager.mu.Unlock()
+	trayManager.deviceCodeItem.Hide()
+}
+
+func (tm *TrayManager) handleDeviceCodeClick() {
+	for {
+		select {
+		case <-tm.deviceCodeItem.ClickedCh:
+			tm.mu.Lock()
+			verificationURL := tm.deviceCodeURL
+			tm.mu.Unlock()
+			if verificationURL != "" {
+				if err := exec.Command("xdg-open", verificationURL).Start(); err != nil {
+					log.Printf("Failed to open verification URL: %v", err)
+				}
+			}
+		case <-tm.ctx.Done():
+			return
+		}
+	}
+}
+
+// handleAccountError marks the account as needing re-authentication when err
+// is caused by its credentials, and notifies the user the first time
+func (tm *TrayManager) handleAccountError(account config.Account, err error) {
+	authErr, ok := calendar.AsAuthError(err)
+	if !ok {
+		return
+	}
+	
+	if tm.setAccountAuthError(account.ID, authErr) {
+		tm.notificationMgr.NotifyReauthRequired(account, authErr.Reason(), func() {
+			tm.reauthenticate(account.ID)
+		})
+	}
+}
+
+// setAccountAuthError records the account's authentication problem, or clears
+// it when authErr is nil, and saves the config. It reports whether the account
+// has just started to need re-authentication.
+func (tm *TrayManager) setAccountAuthError(accountID string, authErr *calendar.AuthError) bool {
+	reason := ""
+	if authErr != nil {
+		reason = authErr.Reason()
+	}
+	
+	// Most refreshes change nothing, so the config is only saved when needed
+	if account, ok := tm.store.Account(accountID); !ok || account.NeedsReauth == (authErr != nil) && account.AuthError == reason {
+		return false
+	}
+	
+	started := false
+	err := tm.store.Update(func(cfg *config.Config) error {
+		for i := range cfg.Accounts {
+			account := &cfg.Accounts[i]
+			if account.ID != accountID {
+				continue
+			}
+			
+			started = authErr != nil && !account.NeedsReauth
+			account.NeedsReauth = authErr != nil
+			account.AuthError = reason
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("Failed to open meeting URL: %v", err)
+		log.Printf("Failed to save account status: %v", err)
 	}
+	return started
+}
+
+// updateReauthItems shows a "Sign in again" item for each of accounts that needs it
+func (tm *TrayManager) updateReauthItems(accounts []config.Account) {
+	if tm.reauthSlots == nil {
+		return
+	}
+	
+	var reauthAccounts []config.Account
+	for _, account := range accounts {
+		if account.NeedsReauth {
+			reauthAccounts = append(reauthAccounts, account)
+		}
+	}
+	tm.mu.Lock()
+	tm.reauthAccounts = reauthAccounts
+	tm.mu.Unlock()
+	
+	for i, slot := range tm.reauthSlots {
+		if i >= len(reauthAccounts) {
+			slot.Hide()
+			continue
+		}
+		
+		account := reauthAccounts[i]
+		slot.SetTitle(fmt.Sprintf("⚠️ Sign in again: %s", account.Email))
+		slot.SetTooltip(fmt.Sprintf("%s. Click to sign in again; your calendar selection is kept.", account.AuthError))
+		slot.Show()
+	}
+}
+
+func (tm *TrayManager) handleReauthClick(index int) {
+	for {
+		select {
+		case <-tm.reauthSlots[index].ClickedCh:
+			tm.mu.Lock()
+			if index < len(tm.reauthAccounts) {
+				go tm.reauthenticate(tm.reauthAccounts[index].ID)
+			}
+			tm.mu.Unlock()
+		case <-tm.ctx.Done():
+			return
+		}
+	}
+}
+
+// reauthenticate restarts the OAuth flow for a single account. The account
+// keeps its place in the config, so its calendar selection is not lost.
+func (tm *TrayManager) reauthenticate(accountID string) {
+	cfg := tm.store.Get()
+	account, ok := tm.store.Account(accountID)
+	if !ok {
+		return
+	}
+	
+	// Signing in again cannot help until the account's OAuth client is configured
+	if _, err := cfg.ClientCredentials(account.OAuthClient); err != nil {
+		tm.openSettings()
+		return
+	}
+	
+	email := account.Email
+	session, err := tm.oauthSessions.StartReauth(tm.ctx, cfg, account, func(signedIn *config.Account, err error) error {
+		if err != nil {
+			log.Printf("Re-authentication of %s failed: %v", email, err)
+			return nil
+		}
+		
+		log.Printf("Re-authenticated account: %s", signedIn.Email)
+		err = tm.store.Update(func(cfg *config.Config) error {
+			for i := range cfg.Accounts {
+				if cfg.Accounts[i].ID == signedIn.ID {
+					cfg.Accounts[i].NeedsReauth = false
+					cfg.Accounts[i].AuthError = ""
+					cfg.Accounts[i].MissingScopes = signedIn.MissingScopes
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to save account status: %v", err)
+		}
+		go tm.refreshMeetings()
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to start re-authentication: %v", err)
+		return
+	}
+	
+	if err := exec.Command("xdg-open", session.AuthURL).Start(); err != nil {
+		log.Printf("Failed to open browser: %v", err)
+	}
+}
+
+func (tm *TrayManager) joinMeeting(meeting *calendar.Meeting) {
+	openMeetingLink(meeting)
 }
 
 func (tm *TrayManager) updateTrayForNoAccounts() {
@@ -571,10 +1127,11 @@ func (tm *TrayManager) updateTrayForCurrentMeeting(meeting *calendar.Meeting) {
 	timeLeft := meeting.EndTime.Sub(now)
 	
 	// Use customizable format
-	title := tm.formatMeetingDisplay(tm.config.CurrentMeetingFormat, meeting, timeLeft, true)
+	format := tm.store.Effective().CurrentMeetingFormat
+	title := tm.formatMeetingDisplay(format, meeting, timeLeft, true)
 	systray.SetTitle(title)
-	systray.SetTooltip(fmt.Sprintf("Currently in meeting: %s\nEnds at %s (%s remaining)", 
-		meeting.Title, 
+	systray.SetTooltip(fmt.Sprintf("Currently in meeting%s\nEnds at %s (%s remaining)", 
+		tooltipTitle(format, meeting), 
 		meeting.EndTime.Format("15:04"), 
 		formatDuration(timeLeft)))
 	tm.titleItem.SetTitle(fmt.Sprintf("▶ %s", tm.truncateTitle(meeting.Title)))
@@ -584,24 +1141,43 @@ func (tm *TrayManager) updateTrayForUpcomingMeeting(meeting *calendar.Meeting) {
 	now := time.Now()
 	timeUntil := meeting.StartTime.Sub(now)
 	
+	format := tm.store.Effective().UpcomingMeetingFormat
 	var title string
 	if timeUntil < time.Minute {
-		title = fmt.Sprintf("%s starting now", tm.truncateTitle(meeting.Title))
+		if hidesTitles(format) {
+			title = "Meeting starting now"
+		} else {
+			title = fmt.Sprintf("%s starting now", tm.truncateTitle(meeting.Title))
+		}
 	} else {
 		// Use customizable format
-		title = tm.formatMeetingDisplay(tm.config.UpcomingMeetingFormat, meeting, timeUntil, false)
+		title = tm.formatMeetingDisplay(format, meeting, timeUntil, false)
 	}
 	
 	systray.SetTitle(title)
-	systray.SetTooltip(fmt.Sprintf("Next meeting: %s\nStarts at %s (in %s)", 
-		meeting.Title, 
+	systray.SetTooltip(fmt.Sprintf("Next meeting%s\nStarts at %s (in %s)", 
+		tooltipTitle(format, meeting), 
 		meeting.StartTime.Format("15:04"), 
 		formatDuration(timeUntil)))
 	tm.titleItem.SetTitle(fmt.Sprintf("Next: %s", tm.truncateTitle(meeting.Title)))
 }
 
+// hidesTitles reports whether a tray title format leaves out the meeting title,
+// e.g. in a profile for presenting; the tooltip then leaves it out as well
+func hidesTitles(format string) bool {
+	return !strings.Contains(format, "{title}")
+}
+
+// tooltipTitle returns ": <title>" for the tray tooltip, or nothing when the format hides titles
+func tooltipTitle(format string, meeting *calendar.Meeting) string {
+	if hidesTitles(format) {
+		return ""
+	}
+	return ": " + meeting.Title
+}
+
 func (tm *TrayManager) truncateTitle(title string) string {
-	maxLength := tm.config.MaxTitleLength
+	maxLength := tm.store.Effective().MaxTitleLength
 	if maxLength <= 0 {
 		maxLength = 25 // fallback to default
 	}
@@ -665,9 +1241,81 @@ func (tm *TrayManager) openSettings() {
 	// Note: Refresh callback is handled by the settings manager
 }
 
-func (tm *TrayManager) reloadCalendarService() {
-	log.Printf("Reloading calendar service with backend: %s", tm.config.CalendarBackend)
-	tm.calendarService = calendar.NewUnifiedCalendarService(tm.ctx, tm.config)
+// applySettings brings the refresh ticker and the tray menu in line with
+// changed settings. It is subscribed to the settings store.
+func (tm *TrayManager) applySettings(cfg *config.Config) {
+	tm.mu.Lock()
+	previous := tm.applied
+	tm.applied = cfg
+	tm.mu.Unlock()
+	
+	// Move the tokens right away if the secret store changed
+	if cfg.SecretStore != previous.SecretStore {
+		config.UseSecretStore(cfg.SecretStore)
+		config.MigrateSecrets(cfg)
+	}
+	if interval := cfg.GetRefreshDuration(); interval != previous.GetRefreshDuration() {
+		log.Printf("Refresh interval changed to %v", interval)
+		tm.ticker.Reset(interval)
+	}
+	
+	tm.updateReauthItems(cfg.Accounts)
+	tm.updateProfileItems(cfg)
+	if !onlyAccountStatusChanged(previous, cfg) {
+		go tm.refreshMeetings()
+	}
+}
+
+// onlyAccountStatusChanged reports whether two settings differ at most in the
+// accounts' authentication status, which refreshing records itself
+func onlyAccountStatusChanged(a, b *config.Config) bool {
+	a, b = a.Clone(), b.Clone()
+	for _, cfg := range []*config.Config{a, b} {
+		for i := range cfg.Accounts {
+			cfg.Accounts[i].NeedsReauth = false
+			cfg.Accounts[i].AuthError = ""
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// runCommand carries out a command given on the command line, here or in a
+// later launch
+func (tm *TrayManager) runCommand(command Command) error {
+	switch command {
+	case CommandNone:
+		return nil
+	case CommandOpenSettings:
+		go tm.openSettings()
+	case CommandRefresh:
+		go tm.refreshMeetings()
+	case CommandJoinNext:
+		return tm.joinNextMeeting()
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+	return nil
+}
+
+// joinNextMeeting opens the link of the meeting in progress, or else of the
+// next meeting that has one
+func (tm *TrayManager) joinNextMeeting() error {
+	tm.mu.Lock()
+	currentMeeting, upcomingMeetings := splitMeetings(tm.meetings, time.Now())
+	tm.mu.Unlock()
+	
+	candidates := upcomingMeetings
+	if currentMeeting != nil {
+		candidates = append([]calendar.Meeting{*currentMeeting}, upcomingMeetings...)
+	}
+	for i := range candidates {
+		if candidates[i].MeetingLink != nil {
+			log.Printf("Joining %s", candidates[i].Title)
+			tm.joinMeeting(&candidates[i])
+			return nil
+		}
+	}
+	return errors.New("no current or upcoming meeting has a link")
 }
 
 func (tm *TrayManager) cleanup() {
@@ -677,6 +1325,7 @@ func (tm *TrayManager) cleanup() {
 	if tm.cancel != nil {
 		tm.cancel()
 	}
+	tm.instance.Close()
 }
 
 // Calendar icon - a simple 16x16 PNG calendar icon
@@ -2,12 +2,19 @@ package ui
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
+	"net"
 	"net/http"
 	"os/exec"
+	"strings"
+	"sync"
 	"time"
 
 	"meetingbar/calendar"
@@ -15,12 +22,20 @@ import (
 )
 
 type WebSettingsManager struct {
-	config          *config.Config
+	store           *config.Store
 	calendarService *calendar.UnifiedCalendarService
 	notificationMgr *NotificationManager
+	oauthSessions   *calendar.OAuthSessionManager
 	ctx             context.Context
-	server          *http.Server
-	port            int
+	
+	// mu guards server and url, the running settings server and the address that opens it
+	mu     sync.Mutex
+	server *http.Server
+	url    string
+
+	// token is handed to the browser that opens the settings and required on
+	// every request, so other local programs and web pages cannot use the API
+	token string
 }
 
 type SettingsPageData struct {
@@ -38,10 +53,13 @@ type APIResponse struct {
 }
 
 type AccountInfo struct {
-	ID      string `json:"id"`
-	Email   string `json:"email"`
-	Avatar  string `json:"avatar"`
-	AddedAt string `json:"addedAt"`
+	ID          string `json:"id"`
+	Email       string `json:"email"`
+	Avatar      string `json:"avatar"`
+	AddedAt     string `json:"addedAt"`
+	NeedsReauth bool   `json:"needsReauth"`
+	AuthError   string `json:"authError"`
+	OAuthClient string `json:"oauthClient"`
 }
 
 type AccountCalendarsInfo struct {
@@ -59,17 +77,36 @@ type CalendarInfo struct {
 	Selected    bool   `json:"selected"`
 }
 
-func NewWebSettingsManager(cfg *config.Config, ctx context.Context) *WebSettingsManager {
+// NewWebSettingsManager creates the web settings. Test notifications and the
+// reminder history go through notificationMgr, the tray's manager, so that
+// only one copy of the reminder state and history is kept.
+func NewWebSettingsManager(store *config.Store, ctx context.Context, notificationMgr *NotificationManager) *WebSettingsManager {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		// Without a token every request is refused
+		log.Printf("Failed to generate settings token: %v", err)
+	}
 	return &WebSettingsManager{
-		config:          cfg,
-		calendarService: calendar.NewUnifiedCalendarService(ctx, cfg),
-		notificationMgr: NewNotificationManager(cfg),
+		store:           store,
+		calendarService: calendar.NewUnifiedCalendarService(ctx, store),
+		notificationMgr: notificationMgr,
+		oauthSessions:   calendar.NewOAuthSessionManager(),
 		ctx:             ctx,
-		port:            8765, // Different port from OAuth callback
+		token:           base64.RawURLEncoding.EncodeToString(token),
 	}
 }
 
+// ShowSettings opens the settings in the browser. The first call starts the
+// settings server on a port the system picks, so instances never compete for
+// one, and blocks until it is closed; later calls open the running one.
 func (wsm *WebSettingsManager) ShowSettings() error {
+	wsm.mu.Lock()
+	if wsm.server != nil {
+		url := wsm.url
+		wsm.mu.Unlock()
+		return exec.Command("xdg-open", url).Start()
+	}
+	
 	// Set up HTTP routes
 	mux := http.NewServeMux()
 	
@@ -80,7 +117,7 @@ func (wsm *WebSettingsManager) ShowSettings() error {
 	mux.HandleFunc("/calendars", wsm.handleCalendarsPage)
 	mux.HandleFunc("/notifications", wsm.handleNotificationsPage)
 	mux.HandleFunc("/general", wsm.handleGeneralPage)
-	mux.HandleFunc("/oauth-success", wsm.handleOAuthSuccess)
+	mux.HandleFunc("/history", wsm.handleHistoryPage)
 	
 	// API endpoints
 	mux.HandleFunc("/api/oauth2", wsm.handleOAuth2API)
@@ -89,16 +126,24 @@ func (wsm *WebSettingsManager) ShowSettings() error {
 	mux.HandleFunc("/api/notifications", wsm.handleNotificationsAPI)
 	mux.HandleFunc("/api/general", wsm.handleGeneralAPI)
 	mux.HandleFunc("/api/add-account", wsm.handleAddAccountAPI)
+	mux.HandleFunc("/api/oauth-session", wsm.handleOAuthSessionAPI)
 	mux.HandleFunc("/api/remove-account", wsm.handleRemoveAccountAPI)
 	
-	// Start server
-	wsm.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", wsm.port),
-		Handler: mux,
+	// Start server, reachable from this machine only
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		wsm.mu.Unlock()
+		return fmt.Errorf("failed to start settings server: %w", err)
+	}
+	addr := listener.Addr().String()
+	server := &http.Server{
+		Handler: wsm.requireToken(addr, mux),
 	}
 	
-	// Open browser
-	url := fmt.Sprintf("http://localhost:%d", wsm.port)
+	// Open browser; the token in the URL is exchanged for a cookie on the first request
+	url := fmt.Sprintf("http://%s/?%s=%s", addr, settingsTokenParam, wsm.token)
+	wsm.server, wsm.url = server, url
+	wsm.mu.Unlock()
 	fmt.Printf("Opening settings in browser: %s\n", url)
 	
 	go func() {
@@ -107,10 +152,17 @@ func (wsm *WebSettingsManager) ShowSettings() error {
 	}()
 	
 	// Start server (blocks until closed)
-	fmt.Printf("Settings server running on %s\n", url)
+	fmt.Printf("Settings server running on http://%s\n", addr)
 	fmt.Println("Close this window when done with settings.")
 	
-	err := wsm.server.ListenAndServe()
+	err = server.Serve(listener)
+	
+	wsm.mu.Lock()
+	if wsm.server == server {
+		wsm.server = nil
+	}
+	wsm.mu.Unlock()
+	
 	if err != http.ErrServerClosed {
 		return fmt.Errorf("settings server error: %w", err)
 	}
@@ -118,15 +170,75 @@ func (wsm *WebSettingsManager) ShowSettings() error {
 	return nil
 }
 
+// settingsTokenParam is the URL parameter that carries the token when the
+// browser is opened; a cookie named settingsTokenCookie and the port carries
+// it on the requests after that, since cookies are shared by all ports
+const (
+	settingsTokenParam  = "token"
+	settingsTokenCookie = "meetingbar_settings"
+)
+
+// requireToken refuses requests that do not carry the settings token, or that
+// come through another host name than addr, as DNS rebinding pages do. The
+// cookie is SameSite=Strict, so requests made by other sites never carry it.
+func (wsm *WebSettingsManager) requireToken(addr string, next http.Handler) http.Handler {
+	_, port, _ := net.SplitHostPort(addr)
+	cookieName := settingsTokenCookie + "_" + port
+	validToken := func(token string) bool {
+		return wsm.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(wsm.token)) == 1
+	}
+	
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != addr {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		
+		query := r.URL.Query()
+		if token := query.Get(settingsTokenParam); token != "" && validToken(token) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     cookieName,
+				Value:    wsm.token,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			// Keep the token out of the address bar and the history
+			query.Del(settingsTokenParam)
+			target := *r.URL
+			target.RawQuery = query.Encode()
+			http.Redirect(w, r, target.String(), http.StatusSeeOther)
+			return
+		}
+		
+		cookie, err := r.Cookie(cookieName)
+		if err != nil || !validToken(cookie.Value) {
+			http.Error(w, "Open the settings from the MeetingBar menu", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); r.Method != http.MethodGet && origin != "" && origin != "http://"+addr {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (wsm *WebSettingsManager) Close() {
-	if wsm.server != nil {
+	wsm.mu.Lock()
+	server := wsm.server
+	wsm.server = nil
+	wsm.mu.Unlock()
+	
+	if server != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		wsm.server.Shutdown(ctx)
+		server.Shutdown(ctx)
 	}
 }
 
 func (wsm *WebSettingsManager) handleHome(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -442,10 +554,10 @@ func (wsm *WebSettingsManager) handleHome(w http.ResponseWriter, r *http.Request
 </html>`
 
 	data := SettingsPageData{
-		Config:         wsm.config,
-		OAuth2Set:      wsm.config.OAuth2.ClientID != "" && wsm.config.OAuth2.ClientSecret != "",
-		AccountsCount:  len(wsm.config.Accounts),
-		CalendarsCount: len(wsm.config.EnabledCalendars),
+		Config:         cfg,
+		OAuth2Set:      cfg.OAuth2.ClientID != "" && cfg.OAuth2.ClientSecret != "",
+		AccountsCount:  len(cfg.Accounts),
+		CalendarsCount: len(cfg.EnabledCalendars),
 		NotificationStatus: wsm.getNotificationStatus(),
 	}
 
@@ -460,6 +572,7 @@ func (wsm *WebSettingsManager) handleHome(w http.ResponseWriter, r *http.Request
 }
 
 func (wsm *WebSettingsManager) handleOAuth2Page(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -521,6 +634,13 @@ func (wsm *WebSettingsManager) handleOAuth2Page(w http.ResponseWriter, r *http.R
             border-color: #3b82f6;
         }
         
+        .read-only-note {
+            display: block;
+            color: #64748b;
+            font-size: 0.85rem;
+            margin-top: 5px;
+        }
+        
         .btn {
             display: inline-block;
             padding: 12px 24px;
@@ -618,7 +738,7 @@ func (wsm *WebSettingsManager) handleOAuth2Page(w http.ResponseWriter, r *http.R
                     <li>Create <strong>OAuth 2.0 Client IDs</strong>:
                         <ul style="margin-top: 5px;">
                             <li>Application type: <strong>Desktop application</strong></li>
-                            <li>Authorized redirect URIs: <strong>http://localhost:8080/callback</strong></li>
+                            <li>No redirect URI is needed: MeetingBar receives the sign-in on a random local port</li>
                         </ul>
                     </li>
                     <li>Copy the Client ID and Client Secret to the form below</li>
@@ -638,17 +758,32 @@ func (wsm *WebSettingsManager) handleOAuth2Page(w http.ResponseWriter, r *http.R
             <form id="oauth2Form">
                 <div class="form-group">
                     <label for="clientId">Google OAuth2 Client ID:</label>
-                    <input type="text" id="clientId" name="clientId" placeholder="your-client-id.googleusercontent.com" value="{{.Config.OAuth2.ClientID}}">
+                    <input type="text" id="clientId" name="clientId" placeholder="your-client-id.googleusercontent.com" value="{{.Config.OAuth2.ClientID}}" {{if .ReadOnly}}disabled{{end}}>
+                    {{if .ReadOnly}}<small class="read-only-note">🔒 The client ID is {{.ReadOnly}}</small>{{end}}
                 </div>
                 
                 <div class="form-group">
                     <label for="clientSecret">Google OAuth2 Client Secret:</label>
-                    <input type="password" id="clientSecret" name="clientSecret" placeholder="Your client secret" value="{{.Config.OAuth2.ClientSecret}}">
+                    <input type="password" id="clientSecret" name="clientSecret" placeholder="{{if .Config.OAuth2.ClientSecret}}Saved in the secret store, leave empty to keep it{{else}}Your client secret{{end}}">
                 </div>
                 
                 <button type="submit" class="btn">💾 Save Credentials</button>
+                {{if not .ReadOnly}}
                 <button type="button" class="btn btn-danger" onclick="clearCredentials()">🗑️ Clear Credentials</button>
+                {{end}}
             </form>
+            
+            <div class="instructions" style="margin-top: 20px;">
+                <h3>🏢 Additional OAuth Clients</h3>
+                <p>Accounts that need a different OAuth client, for example one approved by your Google Workspace admin, can use a client listed under <code>oauth_clients</code> in config.json. Choose it when adding the account.</p>
+                {{if .Config.OAuthClients}}
+                <ul style="margin-top: 10px;">
+                    {{range .Config.OAuthClients}}
+                    <li><strong>{{.Name}}</strong>: {{.ClientID}}{{if not .ClientSecret}} (no client secret){{end}}</li>
+                    {{end}}
+                </ul>
+                {{end}}
+            </div>
         </div>
     </div>
     
@@ -712,10 +847,12 @@ func (wsm *WebSettingsManager) handleOAuth2Page(w http.ResponseWriter, r *http.R
 		Config           *config.Config
 		OAuth2Set        bool
 		ClientIDPreview  string
+		ReadOnly         string
 	}{
-		Config:    wsm.config,
-		OAuth2Set: wsm.config.OAuth2.ClientID != "" && wsm.config.OAuth2.ClientSecret != "",
+		Config:    cfg,
+		OAuth2Set: cfg.OAuth2.ClientID != "" && cfg.OAuth2.ClientSecret != "",
 		ClientIDPreview: wsm.getClientIDPreview(),
+		ReadOnly:  config.ReadOnlySettings()["oauth2"],
 	}
 
 	t, err := template.New("oauth2").Parse(tmpl)
@@ -729,6 +866,7 @@ func (wsm *WebSettingsManager) handleOAuth2Page(w http.ResponseWriter, r *http.R
 }
 
 func (wsm *WebSettingsManager) handleOAuth2API(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	w.Header().Set("Content-Type", "application/json")
 
 	switch r.Method {
@@ -743,15 +881,26 @@ func (wsm *WebSettingsManager) handleOAuth2API(w http.ResponseWriter, r *http.Re
 			return
 		}
 
+		// The saved secret is never sent to the page, so an empty field keeps it
+		if data.ClientSecret == "" {
+			data.ClientSecret = cfg.OAuth2.ClientSecret
+		}
+		// Only the secret can be entered for a client ID set by the administrator
+		if config.IsReadOnly("oauth2") {
+			data.ClientID = cfg.OAuth2.ClientID
+		}
+
 		if data.ClientID == "" || data.ClientSecret == "" {
 			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Both Client ID and Client Secret are required"})
 			return
 		}
 
-		wsm.config.OAuth2.ClientID = data.ClientID
-		wsm.config.OAuth2.ClientSecret = data.ClientSecret
-
-		if err := wsm.config.Save(); err != nil {
+		err := wsm.store.Update(func(cfg *config.Config) error {
+			cfg.OAuth2.ClientID = data.ClientID
+			cfg.OAuth2.ClientSecret = data.ClientSecret
+			return nil
+		})
+		if err != nil {
 			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
 			return
 		}
@@ -759,10 +908,16 @@ func (wsm *WebSettingsManager) handleOAuth2API(w http.ResponseWriter, r *http.Re
 		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "OAuth2 credentials saved successfully"})
 
 	case "DELETE":
-		wsm.config.OAuth2.ClientID = ""
-		wsm.config.OAuth2.ClientSecret = ""
-
-		if err := wsm.config.Save(); err != nil {
+		if reason, ok := config.ReadOnlySettings()["oauth2"]; ok {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "The OAuth2 credentials are " + reason})
+			return
+		}
+		err := wsm.store.Update(func(cfg *config.Config) error {
+			cfg.OAuth2.ClientID = ""
+			cfg.OAuth2.ClientSecret = ""
+			return nil
+		})
+		if err != nil {
 			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
 			return
 		}
@@ -775,6 +930,7 @@ func (wsm *WebSettingsManager) handleOAuth2API(w http.ResponseWriter, r *http.Re
 }
 
 func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -922,6 +1078,69 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
             margin-bottom: 20px;
         }
         
+        .reauth-badge {
+            color: #b45309;
+            font-weight: 500;
+        }
+        
+        .device-code {
+            margin-top: 20px;
+            padding: 20px;
+            background: #f0f9ff;
+            border: 1px solid #0ea5e9;
+            border-radius: 8px;
+        }
+        
+        .device-code p {
+            margin-bottom: 10px;
+        }
+        
+        .oauth-progress {
+            margin-top: 20px;
+            padding: 15px 20px;
+            background: #f7fafc;
+            border: 1px solid #cbd5e0;
+            border-radius: 8px;
+        }
+        
+        .oauth-progress.succeeded {
+            background: #f0fdf4;
+            border-color: #10b981;
+        }
+        
+        .oauth-progress.failed {
+            background: #fef2f2;
+            border-color: #ef4444;
+        }
+        
+        .oauth-progress p {
+            margin-bottom: 10px;
+        }
+        
+        .user-code {
+            font-family: monospace;
+            font-size: 2rem;
+            font-weight: bold;
+            letter-spacing: 4px;
+            color: #0c4a6e;
+            margin: 10px 0 15px;
+        }
+        
+        .form-group {
+            margin-bottom: 15px;
+        }
+        
+        .form-group label {
+            margin-right: 8px;
+            color: #374151;
+        }
+        
+        .form-group select {
+            padding: 8px;
+            border: 1px solid #d1d5db;
+            border-radius: 6px;
+        }
+        
         .instructions {
             background: #f0f9ff;
             border: 1px solid #0ea5e9;
@@ -979,9 +1198,12 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
                         <div class="account-details">
                             <h3>{{.Email}}</h3>
                             <p>Added: {{.AddedAt}}</p>
+                            {{if .OAuthClient}}<p>OAuth client: {{.OAuthClient}}</p>{{end}}
+                            {{if .NeedsReauth}}<p class="reauth-badge">⚠️ Needs sign-in: {{.AuthError}}</p>{{end}}
                         </div>
                     </div>
                     <div class="account-actions">
+                        {{if .NeedsReauth}}<button class="btn btn-success" onclick="reauthAccount('{{.ID}}')">🔑 Sign in again</button>{{end}}
                         <button class="btn" onclick="refreshAccount('{{.ID}}')">🔄 Refresh</button>
                         <button class="btn btn-danger" onclick="removeAccount('{{.ID}}')">🗑️ Remove</button>
                     </div>
@@ -995,7 +1217,29 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
                 <p>Connect another Google account to access more calendars</p>
                 
                 {{if .OAuth2Set}}
-                <button class="btn btn-success" onclick="addAccount()">+ Add Google Account</button>
+                {{if .Config.OAuthClients}}
+                <div class="form-group">
+                    <label for="oauthClient">OAuth client:</label>
+                    <select id="oauthClient">
+                        <option value="">Default client</option>
+                        {{range .Config.OAuthClients}}<option value="{{.Name}}">{{.Name}}</option>{{end}}
+                    </select>
+                </div>
+                {{end}}
+                <button class="btn btn-success" id="addAccountBtn" onclick="addAccount()">+ Add Google Account</button>
+                <button class="btn" id="deviceCodeBtn" onclick="addAccountWithCode()">📟 Sign in with a code</button>
+                
+                <div class="device-code" id="deviceCode" style="display: none;">
+                    <p>Open <a id="deviceCodeUrl" target="_blank" rel="noopener"></a> on any device and enter:</p>
+                    <div class="user-code" id="deviceUserCode"></div>
+                    <p>The code expires at <span id="deviceCodeExpiry"></span>.</p>
+                </div>
+                
+                <div class="oauth-progress" id="oauthProgress" style="display: none;">
+                    <p id="oauthStatus"></p>
+                    <p id="oauthLinkRow" style="display: none;">If no tab opened, <a id="oauthLink" target="_blank" rel="noopener">continue to Google</a>.</p>
+                    <button class="btn btn-danger" id="oauthCancelBtn" onclick="cancelSignIn()">Cancel</button>
+                </div>
                 {{else}}
                 <a href="/oauth2" class="btn">Configure OAuth2 First</a>
                 {{end}}
@@ -1004,40 +1248,179 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
             {{if .OAuth2Set}}
             <div class="instructions">
                 <h4>📋 How it works:</h4>
-                <p>When you click "Add Google Account", you'll be redirected to Google's login page. After signing in and granting permissions, your account will be automatically added to MeetingBar. This may take a few moments to complete.</p>
+                <p>When you click "Add Google Account", you'll be redirected to Google's login page. After signing in and granting permissions, your account will be added to MeetingBar automatically. This page shows the progress and reloads once the account has been added.</p>
+                <p style="margin-top: 10px;">🔐 Sign-in tokens are stored in: <strong>{{.SecretStore}}</strong> (see General settings).</p>
+                <p style="margin-top: 10px;">If no browser can be opened on this machine, for example over SSH, use "Sign in with a code" and approve access from your phone or another computer. The code is also shown in the tray menu. This needs an OAuth client of type "TVs and Limited Input devices".</p>
             </div>
             {{end}}
         </div>
     </div>
     
     <script>
+        let oauthSessionId = null;
+        let oauthPollTimer = null;
+        
         async function addAccount() {
+            // Open the tab right away so the popup blocker allows it
+            const authTab = window.open('', '_blank');
+            const result = await startSignIn({ client: selectedClient() });
+            if (!result) {
+                if (authTab) authTab.close();
+                return;
+            }
+            
+            document.getElementById('oauthLink').href = result.authUrl;
+            document.getElementById('oauthLinkRow').style.display = 'block';
+            if (authTab) {
+                authTab.location.href = result.authUrl;
+            }
+            showSignInStatus(result);
+        }
+        
+        async function reauthAccount(accountId) {
+            const authTab = window.open('', '_blank');
+            const result = await startSignIn({ mode: 'reauth', accountId: accountId });
+            if (!result) {
+                if (authTab) authTab.close();
+                return;
+            }
+            
+            document.getElementById('oauthLink').href = result.authUrl;
+            document.getElementById('oauthLinkRow').style.display = 'block';
+            if (authTab) {
+                authTab.location.href = result.authUrl;
+            }
+            showSignInStatus(result);
+        }
+        
+        async function addAccountWithCode() {
+            const result = await startSignIn({ mode: 'device', client: selectedClient() });
+            if (!result) {
+                return;
+            }
+            
+            const link = document.getElementById('deviceCodeUrl');
+            link.href = result.verificationUrl;
+            link.textContent = result.verificationUrl;
+            document.getElementById('deviceUserCode').textContent = result.userCode;
+            document.getElementById('deviceCodeExpiry').textContent = result.expiresAt;
+            document.getElementById('deviceCode').style.display = 'block';
+            showSignInStatus(result);
+        }
+        
+        function selectedClient() {
+            const select = document.getElementById('oauthClient');
+            return select ? select.value : '';
+        }
+        
+        async function startSignIn(request) {
+            setSignInButtonsDisabled(true);
+            
             try {
-                // Show loading state
-                document.querySelector('button[onclick="addAccount()"]').textContent = 'Starting authentication...';
-                document.querySelector('button[onclick="addAccount()"]').disabled = true;
-                
                 const response = await fetch('/api/add-account', {
-                    method: 'POST'
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify(request)
                 });
                 
                 const result = await response.json();
                 
-                if (result.success && result.data && result.data.authUrl) {
-                    alert('ℹ️ You will be redirected to Google for authentication. After completing the process, please refresh this page to see your new account.');
-                    // Open Google OAuth URL in current window
-                    window.location.href = result.data.authUrl;
-                } else {
-                    alert('❌ Error: ' + (result.message || 'Failed to start authentication'));
-                    // Reset button
-                    document.querySelector('button[onclick="addAccount()"]').textContent = '+ Add Google Account';
-                    document.querySelector('button[onclick="addAccount()"]').disabled = false;
+                if (result.success && result.data) {
+                    oauthSessionId = result.data.sessionId;
+                    oauthPollTimer = setInterval(pollSignIn, 1500);
+                    return result.data;
                 }
+                alert('❌ Error: ' + (result.message || 'Failed to start authentication'));
             } catch (error) {
                 alert('❌ Error adding account: ' + error.message);
-                // Reset button
-                document.querySelector('button[onclick="addAccount()"]').textContent = '+ Add Google Account';
-                document.querySelector('button[onclick="addAccount()"]').disabled = false;
+            }
+            
+            setSignInButtonsDisabled(false);
+            return null;
+        }
+        
+        async function pollSignIn() {
+            if (!oauthSessionId) {
+                return;
+            }
+            
+            try {
+                const response = await fetch('/api/oauth-session?id=' + encodeURIComponent(oauthSessionId));
+                const result = await response.json();
+                
+                if (result.success && result.data) {
+                    showSignInStatus(result.data);
+                } else {
+                    finishSignIn();
+                }
+            } catch (error) {
+                // The settings server may be busy; try again on the next tick
+            }
+        }
+        
+        function showSignInStatus(session) {
+            const box = document.getElementById('oauthProgress');
+            const status = document.getElementById('oauthStatus');
+            box.style.display = 'block';
+            box.className = 'oauth-progress ' + session.status;
+            
+            switch (session.status) {
+                case 'waiting':
+                    status.textContent = session.mode === 'device'
+                        ? '⏳ Waiting for you to approve access on another device...'
+                        : '⏳ Waiting for you to finish signing in with Google...';
+                    return;
+                case 'succeeded':
+                    finishSignIn();
+                    if (session.warning) {
+                        status.textContent = '⚠️ Signed in as ' + (session.email || 'your account') + ', but: ' + session.warning;
+                        box.className = 'oauth-progress failed';
+                        return;
+                    }
+                    status.textContent = '✅ Signed in as ' + (session.email || 'your account') + '. Reloading...';
+                    setTimeout(() => location.reload(), 1500);
+                    return;
+                case 'cancelled':
+                    status.textContent = 'Sign-in cancelled.';
+                    break;
+                default:
+                    status.textContent = '❌ Sign-in ' + session.statusText;
+            }
+            finishSignIn();
+        }
+        
+        function finishSignIn() {
+            clearInterval(oauthPollTimer);
+            oauthPollTimer = null;
+            oauthSessionId = null;
+            document.getElementById('oauthCancelBtn').style.display = 'none';
+            document.getElementById('oauthLinkRow').style.display = 'none';
+            document.getElementById('deviceCode').style.display = 'none';
+            setSignInButtonsDisabled(false);
+        }
+        
+        async function cancelSignIn() {
+            if (!oauthSessionId) {
+                return;
+            }
+            
+            try {
+                await fetch('/api/oauth-session', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ action: 'cancel', id: oauthSessionId })
+                });
+                await pollSignIn();
+            } catch (error) {
+                alert('❌ Error cancelling sign-in: ' + error.message);
+            }
+        }
+        
+        function setSignInButtonsDisabled(disabled) {
+            document.getElementById('addAccountBtn').disabled = disabled;
+            document.getElementById('deviceCodeBtn').disabled = disabled;
+            if (disabled) {
+                document.getElementById('oauthCancelBtn').style.display = 'inline-block';
             }
         }
         
@@ -1056,7 +1439,7 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
                 const result = await response.json();
                 
                 if (result.success) {
-                    alert('✅ Account removed successfully!');
+                    alert((result.data && result.data.revoked === false ? '⚠️ ' : '✅ ') + result.message);
                     location.reload();
                 } else {
                     alert('❌ Error: ' + result.message);
@@ -1091,13 +1474,15 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
 </html>`
 
 	data := struct {
-		Config    *config.Config
-		OAuth2Set bool
-		Accounts  []AccountInfo
+		Config      *config.Config
+		OAuth2Set   bool
+		Accounts    []AccountInfo
+		SecretStore string
 	}{
-		Config:    wsm.config,
-		OAuth2Set: wsm.config.OAuth2.ClientID != "" && wsm.config.OAuth2.ClientSecret != "",
-		Accounts:  wsm.getAccountsInfo(),
+		Config:      cfg,
+		OAuth2Set:   (cfg.OAuth2.ClientID != "" && cfg.OAuth2.ClientSecret != "") || len(cfg.OAuthClients) > 0,
+		Accounts:    wsm.getAccountsInfo(),
+		SecretStore: config.ActiveSecretStore().Description(),
 	}
 
 	t, err := template.New("accounts").Parse(tmpl)
@@ -1111,6 +1496,7 @@ func (wsm *WebSettingsManager) handleAccountsPage(w http.ResponseWriter, r *http
 }
 
 func (wsm *WebSettingsManager) handleCalendarsPage(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -1279,6 +1665,12 @@ func (wsm *WebSettingsManager) handleCalendarsPage(w http.ResponseWriter, r *htt
             border-top: 1px solid #e2e8f0;
         }
         
+        .read-only-note {
+            text-align: center;
+            color: #64748b;
+            margin-top: 30px;
+        }
+        
         .warning {
             background: #fef3c7;
             border: 1px solid #f59e0b;
@@ -1359,11 +1751,15 @@ func (wsm *WebSettingsManager) handleCalendarsPage(w http.ResponseWriter, r *htt
             </div>
             {{end}}
             
+            {{if .ReadOnly}}
+            <p class="read-only-note">🔒 The calendar selection is {{.ReadOnly}}</p>
+            {{else}}
             <div class="actions">
                 <button class="btn btn-success" onclick="saveCalendarSelection()">💾 Save Selection</button>
                 <button class="btn" onclick="selectAll()">✅ Select All</button>
                 <button class="btn" onclick="selectNone()">❌ Select None</button>
             </div>
+            {{end}}
             {{else}}
             <div style="text-align: center; padding: 40px;">
                 <a href="/accounts" class="btn">Add Google Accounts First</a>
@@ -1443,10 +1839,12 @@ func (wsm *WebSettingsManager) handleCalendarsPage(w http.ResponseWriter, r *htt
 		Config           *config.Config
 		HasAccounts      bool
 		AccountCalendars []AccountCalendarsInfo
+		ReadOnly         string
 	}{
-		Config:           wsm.config,
+		Config:           cfg,
 		HasAccounts:      len(accountCalendars) > 0, // Check if any calendars are available for current backend
 		AccountCalendars: accountCalendars,
+		ReadOnly:         config.ReadOnlySettings()["enabled_calendars"],
 	}
 
 	t, err := template.New("calendars").Parse(tmpl)
@@ -1460,6 +1858,7 @@ func (wsm *WebSettingsManager) handleCalendarsPage(w http.ResponseWriter, r *htt
 }
 
 func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -1655,6 +2054,14 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
             background: #059669;
         }
         
+        .btn-danger {
+            background: #ef4444;
+        }
+        
+        .btn-danger:hover {
+            background: #dc2626;
+        }
+        
         .actions {
             text-align: center;
             margin-top: 30px;
@@ -1674,6 +2081,44 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
             color: #0c4a6e;
             font-size: 0.9rem;
         }
+        
+        .rule-row {
+            display: flex;
+            gap: 10px;
+            align-items: center;
+            margin-bottom: 10px;
+        }
+        
+        .rule-row input {
+            flex: 1;
+            padding: 10px;
+            border: 2px solid #e5e7eb;
+            border-radius: 6px;
+            font-size: 0.95rem;
+        }
+        
+        .rule-row input[type="time"] {
+            flex: 0 0 120px;
+        }
+        
+        .rule-row .btn {
+            margin: 0;
+            padding: 10px 14px;
+        }
+        
+        .field-error {
+            display: block;
+            color: #dc2626;
+            font-size: 0.85rem;
+            margin-top: 5px;
+        }
+        
+        .read-only-note {
+            display: block;
+            color: #64748b;
+            font-size: 0.85rem;
+            margin-top: 5px;
+        }
     </style>
 </head>
 <body>
@@ -1746,50 +2191,251 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
                         </label>
                     </div>
                 </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Interruptive Reminders</h4>
+                        <p>Show a full-screen join prompt with attendees when a meeting starts</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="interruptiveReminders" {{if .Config.InterruptiveReminders}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
             </div>
             
             <div class="settings-section">
-                <h3><span class="icon">🔊</span> Sound Settings</h3>
+                <h3><span class="icon">⏱</span> Meeting End</h3>
                 
                 <div class="setting-item">
                     <div class="setting-info">
-                        <h4>Notification Sound</h4>
-                        <p>Play a sound when showing meeting notifications</p>
+                        <h4>Meeting Ending Reminder</h4>
+                        <p>Notify shortly before the current meeting is scheduled to end</p>
                     </div>
                     <div class="setting-control">
                         <label class="toggle">
-                            <input type="checkbox" id="notificationSound" {{if .Config.NotificationSound}}checked{{end}}>
+                            <input type="checkbox" id="notifyMeetingEnding" {{if .Config.NotifyMeetingEnding}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Ending Reminder Timing</h4>
+                        <p>How many minutes before the meeting ends to remind you</p>
+                    </div>
+                    <div class="setting-control">
+                        <div class="form-group" style="margin: 0; width: 120px;">
+                            <select id="meetingEndingTime">
+                                <option value="1" {{if eq .Config.MeetingEndingTime 1}}selected{{end}}>1 minute</option>
+                                <option value="2" {{if eq .Config.MeetingEndingTime 2}}selected{{end}}>2 minutes</option>
+                                <option value="5" {{if eq .Config.MeetingEndingTime 5}}selected{{end}}>5 minutes</option>
+                                <option value="10" {{if eq .Config.MeetingEndingTime 10}}selected{{end}}>10 minutes</option>
+                            </select>
+                        </div>
+                    </div>
+                </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Overrun Alert</h4>
+                        <p>Alert with a Join button when the next meeting starts while you are still in one</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="notifyOverlap" {{if .Config.NotifyOverlap}}checked{{end}}>
                             <span class="slider"></span>
                         </label>
                     </div>
                 </div>
             </div>
             
-            <div class="preview">
-                <p><strong>Preview:</strong> {{.PreviewText}}</p>
-            </div>
-            
-            <div class="actions">
-                <button class="btn btn-success" onclick="saveNotificationSettings()">💾 Save Settings</button>
-                <button class="btn" onclick="testNotification()">🗏 Test Notification</button>
-            </div>
-        </div>
-    </div>
-    
-    <script>
-        function updatePreview() {
-            const enabled = document.getElementById('enableNotifications').checked;
-            const time = document.getElementById('notificationTime').value;
-            const showLinks = document.getElementById('showMeetingLinks').checked;
-            const persistent = document.getElementById('persistentNotifications').checked;
-            const sound = document.getElementById('notificationSound').checked;
-            
-            let preview = "Notifications: ";
-            if (enabled) {
-                preview += "Enabled, " + time + " minutes before meetings";
-                if (showLinks) preview += ", with meeting links";
-                if (persistent) preview += ", persistent";
-                if (sound) preview += ", with sound";
+            <div class="settings-section">
+                <h3><span class="icon">📝</span> Meeting Changes</h3>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Rescheduled Meetings</h4>
+                        <p>Notify when an upcoming meeting is moved to a different time</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="notifyRescheduled" {{if .Config.NotifyRescheduled}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Cancelled Meetings</h4>
+                        <p>Notify when an upcoming meeting is cancelled</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="notifyCancelled" {{if .Config.NotifyCancelled}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Meeting Link Changes</h4>
+                        <p>Notify when the join link of an upcoming meeting changes</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="notifyLinkChanged" {{if .Config.NotifyLinkChanged}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>New Meetings Today</h4>
+                        <p>Notify when a meeting is added to today's schedule</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="notifyNewMeetings" {{if .Config.NotifyNewMeetings}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+            </div>
+            
+            <div class="settings-section">
+                <h3><span class="icon">🌙</span> Quiet Hours &amp; Do Not Disturb</h3>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Respect Do Not Disturb</h4>
+                        <p>Treat the desktop's Do Not Disturb mode like quiet hours</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="respectDoNotDisturb" {{if .Config.RespectDoNotDisturb}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>During Quiet Time</h4>
+                        <p>What happens to reminders during quiet hours or Do Not Disturb</p>
+                    </div>
+                    <div class="setting-control">
+                        <div class="form-group" style="margin: 0; width: 200px;">
+                            <select id="quietMode">
+                                <option value="silent" {{if eq .Config.QuietMode "silent"}}selected{{end}}>Show silently</option>
+                                <option value="suppress" {{if eq .Config.QuietMode "suppress"}}selected{{end}}>Don't show</option>
+                            </select>
+                        </div>
+                    </div>
+                </div>
+                
+                <h4 style="margin: 20px 0 5px;">Quiet Hours</h4>
+                <p style="color: #64748b; font-size: 0.9rem; margin-bottom: 10px;">Days such as "sat,sun" (empty for every day) and optional calendar IDs the period is limited to. An end time before the start time spans midnight.</p>
+                <div id="quietHoursList"></div>
+                <button class="btn" onclick="addQuietHoursRow({})">+ Add Quiet Hours</button>
+                
+                <h4 style="margin: 20px 0 5px;">Always Alert</h4>
+                <p style="color: #64748b; font-size: 0.9rem; margin-bottom: 10px;">Meetings whose title contains the text and/or that are on the calendar are announced even during quiet time.</p>
+                <div id="alertRulesList"></div>
+                <button class="btn" onclick="addAlertRuleRow({})">+ Add Rule</button>
+            </div>
+            
+            <div class="settings-section">
+                <h3><span class="icon">🔊</span> Sound Settings</h3>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Notification Sound</h4>
+                        <p>Play a sound when showing meeting notifications</p>
+                    </div>
+                    <div class="setting-control">
+                        <label class="toggle">
+                            <input type="checkbox" id="notificationSound" {{if .Config.NotificationSound}}checked{{end}}>
+                            <span class="slider"></span>
+                        </label>
+                    </div>
+                </div>
+                
+                <p style="color: #64748b; font-size: 0.9rem; margin: 15px 0;">Custom sound files per reminder type. Leave empty to use the sound theme.</p>
+                
+                <div class="form-group">
+                    <label for="soundFileStart">Upcoming meeting:</label>
+                    <div style="display: flex; gap: 10px;">
+                        <input type="text" id="soundFileStart" value="{{index .Config.SoundFiles "start"}}" placeholder="Default: message-new-instant">
+                        <button class="btn" style="margin: 0; white-space: nowrap;" onclick="testSound('start', 'soundFileStart')">🔊 Test</button>
+                    </div>
+                </div>
+                
+                <div class="form-group">
+                    <label for="soundFileEnding">Meeting ending:</label>
+                    <div style="display: flex; gap: 10px;">
+                        <input type="text" id="soundFileEnding" value="{{index .Config.SoundFiles "ending"}}" placeholder="Default: message-new-instant">
+                        <button class="btn" style="margin: 0; white-space: nowrap;" onclick="testSound('ending', 'soundFileEnding')">🔊 Test</button>
+                    </div>
+                </div>
+                
+                <div class="form-group">
+                    <label for="soundFileOverlap">Next meeting started:</label>
+                    <div style="display: flex; gap: 10px;">
+                        <input type="text" id="soundFileOverlap" value="{{index .Config.SoundFiles "overlap"}}" placeholder="Default: alarm-clock-elapsed">
+                        <button class="btn" style="margin: 0; white-space: nowrap;" onclick="testSound('overlap', 'soundFileOverlap')">🔊 Test</button>
+                    </div>
+                </div>
+                
+                <div class="form-group">
+                    <label for="soundFileInterruptive">Interruptive join prompt:</label>
+                    <div style="display: flex; gap: 10px;">
+                        <input type="text" id="soundFileInterruptive" value="{{index .Config.SoundFiles "interruptive"}}" placeholder="Default: alarm-clock-elapsed">
+                        <button class="btn" style="margin: 0; white-space: nowrap;" onclick="testSound('interruptive', 'soundFileInterruptive')">🔊 Test</button>
+                    </div>
+                </div>
+                
+                <div class="form-group">
+                    <label for="soundFileChange">Meeting changes:</label>
+                    <div style="display: flex; gap: 10px;">
+                        <input type="text" id="soundFileChange" value="{{index .Config.SoundFiles "change"}}" placeholder="Default: message-new-instant">
+                        <button class="btn" style="margin: 0; white-space: nowrap;" onclick="testSound('change', 'soundFileChange')">🔊 Test</button>
+                    </div>
+                </div>
+            </div>
+            
+            <div class="preview">
+                <p><strong>Preview:</strong> {{.PreviewText}}</p>
+            </div>
+            
+            <div class="actions">
+                <button class="btn btn-success" onclick="saveNotificationSettings()">💾 Save Settings</button>
+                <button class="btn" onclick="testNotification()">🗏 Test Notification</button>
+                <a href="/history" class="btn">🕘 Reminder History</a>
+            </div>
+        </div>
+    </div>
+    
+    <script>
+        function updatePreview() {
+            const enabled = document.getElementById('enableNotifications').checked;
+            const time = document.getElementById('notificationTime').value;
+            const showLinks = document.getElementById('showMeetingLinks').checked;
+            const persistent = document.getElementById('persistentNotifications').checked;
+            const sound = document.getElementById('notificationSound').checked;
+            
+            let preview = "Notifications: ";
+            if (enabled) {
+                preview += "Enabled, " + time + " minutes before meetings";
+                if (showLinks) preview += ", with meeting links";
+                if (persistent) preview += ", persistent";
+                if (sound) preview += ", with sound";
             } else {
                 preview += "Disabled";
             }
@@ -1802,13 +2448,127 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
             element.addEventListener('change', updatePreview);
         });
         
+        function splitList(value) {
+            return value.split(',').map(item => item.trim()).filter(item => item !== '');
+        }
+        
+        function addRuleRow(listId, fields) {
+            const row = document.createElement('div');
+            row.className = 'rule-row';
+            
+            fields.forEach(field => {
+                const input = document.createElement('input');
+                input.type = field.type || 'text';
+                input.className = field.className;
+                input.placeholder = field.placeholder || '';
+                input.value = field.value || '';
+                row.appendChild(input);
+            });
+            
+            const removeBtn = document.createElement('button');
+            removeBtn.className = 'btn btn-danger';
+            removeBtn.textContent = '✕';
+            removeBtn.onclick = () => row.remove();
+            row.appendChild(removeBtn);
+            
+            document.getElementById(listId).appendChild(row);
+        }
+        
+        function addQuietHoursRow(period) {
+            addRuleRow('quietHoursList', [
+                { className: 'qh-days', placeholder: 'Days, e.g. sat,sun', value: (period.days || []).join(',') },
+                { className: 'qh-start', type: 'time', value: period.start || '22:00' },
+                { className: 'qh-end', type: 'time', value: period.end || '07:00' },
+                { className: 'qh-calendars', placeholder: 'Calendar IDs (optional)', value: (period.calendars || []).join(',') }
+            ]);
+        }
+        
+        function addAlertRuleRow(rule) {
+            addRuleRow('alertRulesList', [
+                { className: 'ar-title', placeholder: 'Title contains', value: rule.title_contains },
+                { className: 'ar-calendar', placeholder: 'Calendar ID (optional)', value: rule.calendar_id }
+            ]);
+        }
+        
+        function collectQuietHours() {
+            return Array.from(document.querySelectorAll('#quietHoursList .rule-row')).map(row => ({
+                days: splitList(row.querySelector('.qh-days').value),
+                start: row.querySelector('.qh-start').value,
+                end: row.querySelector('.qh-end').value,
+                calendars: splitList(row.querySelector('.qh-calendars').value)
+            }));
+        }
+        
+        function collectAlertRules() {
+            return Array.from(document.querySelectorAll('#alertRulesList .rule-row')).map(row => ({
+                title_contains: row.querySelector('.ar-title').value.trim(),
+                calendar_id: row.querySelector('.ar-calendar').value.trim()
+            })).filter(rule => rule.title_contains !== '' || rule.calendar_id !== '');
+        }
+        
+        ({{.Config.QuietHours}} || []).forEach(addQuietHoursRow);
+        ({{.Config.AlwaysAlert}} || []).forEach(addAlertRuleRow);
+        
+        // fieldElement finds the input of a setting named as in config.json, e.g. "quiet_hours[0].start"
+        function fieldElement(field) {
+            const ids = {
+                notification_time: 'notificationTime',
+                meeting_ending_time: 'meetingEndingTime',
+                quiet_mode: 'quietMode'
+            };
+            if (ids[field]) {
+                return document.getElementById(ids[field]);
+            }
+            
+            const quietHours = field.match(/^quiet_hours\[(\d+)\]\.(days|start|end)$/);
+            if (quietHours) {
+                const row = document.querySelectorAll('#quietHoursList .rule-row')[parseInt(quietHours[1])];
+                return row ? row.querySelector('.qh-' + quietHours[2]) : null;
+            }
+            return null;
+        }
+        
+        // showFieldErrors puts the message of each invalid setting below its input
+        function showFieldErrors(fieldErrors) {
+            document.querySelectorAll('.field-error').forEach(el => el.remove());
+            Object.entries(fieldErrors || {}).forEach(([field, message]) => {
+                const input = fieldElement(field);
+                if (!input) {
+                    return;
+                }
+                const note = document.createElement('small');
+                note.className = 'field-error';
+                note.textContent = message;
+                input.insertAdjacentElement('afterend', note);
+            });
+        }
+        
         async function saveNotificationSettings() {
             const settings = {
                 enableNotifications: document.getElementById('enableNotifications').checked,
                 notificationTime: parseInt(document.getElementById('notificationTime').value),
                 showMeetingLinks: document.getElementById('showMeetingLinks').checked,
                 persistentNotifications: document.getElementById('persistentNotifications').checked,
-                notificationSound: document.getElementById('notificationSound').checked
+                interruptiveReminders: document.getElementById('interruptiveReminders').checked,
+                notificationSound: document.getElementById('notificationSound').checked,
+                notifyRescheduled: document.getElementById('notifyRescheduled').checked,
+                notifyCancelled: document.getElementById('notifyCancelled').checked,
+                notifyLinkChanged: document.getElementById('notifyLinkChanged').checked,
+                notifyNewMeetings: document.getElementById('notifyNewMeetings').checked,
+                notifyMeetingEnding: document.getElementById('notifyMeetingEnding').checked,
+                meetingEndingTime: parseInt(document.getElementById('meetingEndingTime').value),
+                notifyOverlap: document.getElementById('notifyOverlap').checked,
+                respectDoNotDisturb: document.getElementById('respectDoNotDisturb').checked,
+                quietMode: document.getElementById('quietMode').value,
+                quietHours: collectQuietHours(),
+                alwaysAlert: collectAlertRules(),
+                soundFiles: {
+                    start: document.getElementById('soundFileStart').value.trim(),
+                    ending: document.getElementById('soundFileEnding').value.trim(),
+                    overlap: document.getElementById('soundFileOverlap').value.trim(),
+                    interruptive: document.getElementById('soundFileInterruptive').value.trim(),
+                    change: document.getElementById('soundFileChange').value.trim()
+                }
             };
             
             try {
@@ -1819,6 +2579,7 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
                 });
                 
                 const result = await response.json();
+                showFieldErrors(result.data && result.data.fieldErrors);
                 
                 if (result.success) {
                     alert('✅ Notification settings saved successfully!');
@@ -1850,6 +2611,66 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
             }
         }
         
+        async function testSound(reminderType, inputId) {
+            try {
+                const response = await fetch('/api/notifications', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({
+                        action: 'test-sound',
+                        reminderType: reminderType,
+                        soundFile: document.getElementById(inputId).value.trim()
+                    })
+                });
+                
+                const result = await response.json();
+                
+                if (!result.success) {
+                    alert('❌ Error: ' + result.message);
+                }
+            } catch (error) {
+                alert('❌ Error playing test sound: ' + error.message);
+            }
+        }
+        
+        // readOnlyInputs returns the inputs of a setting named as in config.json
+        function readOnlyInputs(field) {
+            if (field === 'sound_files') {
+                return Array.from(document.querySelectorAll('[id^="soundFile"], [id^="soundFile"] + button'));
+            }
+            const lists = { quiet_hours: 'quietHoursList', always_alert: 'alertRulesList' };
+            if (lists[field]) {
+                const list = document.getElementById(lists[field]);
+                return [...list.querySelectorAll('input, button'), list.nextElementSibling];
+            }
+            const input = document.getElementById(field.replace(/_([a-z])/g, (match, letter) => letter.toUpperCase()));
+            return input ? [input] : [];
+        }
+        
+        // markReadOnly disables the inputs of settings that cannot be changed
+        // here, such as those locked by an administrator, and says why
+        function markReadOnly(readOnly) {
+            Object.entries(readOnly || {}).forEach(([field, reason]) => {
+                const inputs = readOnlyInputs(field);
+                if (inputs.length === 0) {
+                    return;
+                }
+                inputs.forEach(input => input.disabled = true);
+                
+                const note = document.createElement('small');
+                note.className = 'read-only-note';
+                note.textContent = '🔒 ' + reason;
+                const item = inputs[0].closest('.setting-item');
+                if (item) {
+                    item.querySelector('.setting-info').appendChild(note);
+                } else {
+                    inputs[inputs.length - 1].insertAdjacentElement('afterend', note);
+                }
+            });
+        }
+        
+        markReadOnly({{.ReadOnly}});
+        
         // Initialize preview
         updatePreview();
     </script>
@@ -1859,9 +2680,11 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
 	data := struct {
 		Config      *config.Config
 		PreviewText string
+		ReadOnly    map[string]string
 	}{
-		Config:      wsm.config,
+		Config:      cfg,
 		PreviewText: wsm.getNotificationPreview(),
+		ReadOnly:    config.ReadOnlySettings(),
 	}
 
 	t, err := template.New("notifications").Parse(tmpl)
@@ -1875,6 +2698,7 @@ func (wsm *WebSettingsManager) handleNotificationsPage(w http.ResponseWriter, r
 }
 
 func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -2096,6 +2920,20 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
             max-height: 400px;
             overflow-y: auto;
         }
+        
+        .field-error {
+            display: block;
+            color: #dc2626;
+            font-size: 0.85rem;
+            margin-top: 5px;
+        }
+        
+        .read-only-note {
+            display: block;
+            color: #64748b;
+            font-size: 0.85rem;
+            margin-top: 5px;
+        }
     </style>
 </head>
 <body>
@@ -2127,6 +2965,26 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
                 </div>
             </div>
 
+            <div class="settings-section">
+                <h3><span class="icon">🔐</span> Secret Storage</h3>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Store Sign-in Tokens In</h4>
+                        <p>Currently in use: <strong>{{.SecretStore}}</strong>. Automatic uses the system keyring when a Secret Service (GNOME Keyring, KWallet) is running and an encrypted file in the config directory otherwise. Set MEETINGBAR_SECRET_PASSPHRASE to protect the file with a passphrase instead of a key bound to this machine. Tokens are moved when the store changes.</p>
+                    </div>
+                    <div class="setting-control">
+                        <div class="form-group" style="margin: 0; width: 200px;">
+                            <select id="secretStore">
+                                <option value="auto" {{if eq .Config.SecretStore "auto"}}selected{{end}}>Automatic</option>
+                                <option value="keyring" {{if eq .Config.SecretStore "keyring"}}selected{{end}}>System keyring</option>
+                                <option value="file" {{if eq .Config.SecretStore "file"}}selected{{end}}>Encrypted file</option>
+                            </select>
+                        </div>
+                    </div>
+                </div>
+            </div>
+
             <div class="settings-section">
                 <h3><span class="icon">🔄</span> Refresh Settings</h3>
                 
@@ -2224,17 +3082,32 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
                 
                 <div class="setting-item">
                     <div class="setting-info">
-                        <h4>Start with System</h4>
-                        <p>Automatically start MeetingBar when you log in</p>
+                        <h4>Launch at Login</h4>
+                        <p>Automatically start MeetingBar when you log in. Turning it off in your desktop's startup applications turns it off here too.</p>
                     </div>
                     <div class="setting-control">
                         <label class="toggle">
-                            <input type="checkbox" id="startWithSystem" {{if .Config.StartWithSystem}}checked{{end}}>
+                            <input type="checkbox" id="launchAtLogin" {{if .Config.LaunchAtLogin}}checked{{end}}>
                             <span class="slider"></span>
                         </label>
                     </div>
                 </div>
                 
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Start Using</h4>
+                        <p>An autostart entry works on any desktop. A systemd user service also restarts MeetingBar if it crashes.</p>
+                    </div>
+                    <div class="setting-control">
+                        <div class="form-group" style="margin: 0; width: 200px;">
+                            <select id="autostartMethod">
+                                <option value="desktop" {{if eq .Config.AutostartMethod "desktop"}}selected{{end}}>Autostart entry</option>
+                                <option value="systemd" {{if eq .Config.AutostartMethod "systemd"}}selected{{end}}>systemd user service</option>
+                            </select>
+                        </div>
+                    </div>
+                </div>
+                
                 <div class="setting-item">
                     <div class="setting-info">
                         <h4>Auto-refresh on Startup</h4>
@@ -2249,6 +3122,71 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
                 </div>
             </div>
             
+            <div class="settings-section">
+                <h3><span class="icon">📦</span> Import / Export</h3>
+                
+                <div class="setting-item">
+                    <div class="setting-info">
+                        <h4>Export Settings</h4>
+                        <p>Save all settings, including the OAuth clients, calendar choices and formats, to a file for another machine or a teammate. Accounts and sign-in tokens are never exported. With a passphrase, the client secrets are included, encrypted with it.</p>
+                    </div>
+                    <div class="setting-control">
+                        <div class="form-group" style="margin: 0; width: 120px;">
+                            <select id="exportFormat">
+                                <option value="json">JSON</option>
+                                <option value="toml">TOML</option>
+                            </select>
+                        </div>
+                    </div>
+                </div>
+                
+                <div class="form-group">
+                    <label for="exportPassphrase">Passphrase for the client secrets (optional):</label>
+                    <input type="password" id="exportPassphrase" autocomplete="new-password" placeholder="Leave empty to export without client secrets">
+                </div>
+                <button class="btn" onclick="exportSettings()">📤 Export Settings</button>
+                
+                <div class="setting-item" style="margin-top: 20px;">
+                    <div class="setting-info">
+                        <h4>Import Settings</h4>
+                        <p>Merge keeps the settings the file does not contain; replace resets them to their defaults. Your accounts are kept either way. The changes are shown before anything is saved.</p>
+                    </div>
+                    <div class="setting-control">
+                        <div class="form-group" style="margin: 0; width: 120px;">
+                            <select id="importMode">
+                                <option value="merge">Merge</option>
+                                <option value="replace">Replace</option>
+                            </select>
+                        </div>
+                    </div>
+                </div>
+                
+                <div class="form-group">
+                    <label for="importFile">Settings file:</label>
+                    <input type="file" id="importFile" accept=".json,.toml">
+                </div>
+                <div class="form-group">
+                    <label for="importPassphrase">Passphrase for the client secrets (if the file has them):</label>
+                    <input type="password" id="importPassphrase" autocomplete="off" placeholder="Leave empty to keep your current client secrets">
+                </div>
+                <button class="btn" onclick="previewImport()">🔍 Preview Import</button>
+                
+                <div id="importPreview" style="display: none; margin-top: 15px;">
+                    <p id="importSummary" style="margin-bottom: 10px;"></p>
+                    <table style="width: 100%; border-collapse: collapse; font-size: 0.85rem;">
+                        <thead>
+                            <tr style="text-align: left; border-bottom: 1px solid #e2e8f0;">
+                                <th style="padding: 6px;">Setting</th>
+                                <th style="padding: 6px;">Current</th>
+                                <th style="padding: 6px;">Imported</th>
+                            </tr>
+                        </thead>
+                        <tbody id="importChanges"></tbody>
+                    </table>
+                    <button class="btn btn-success" id="applyImport" style="margin-top: 10px;" onclick="applyImport()">📥 Apply Import</button>
+                </div>
+            </div>
+            
             <div class="settings-section">
                 <h3><span class="icon">📄</span> Configuration File</h3>
                 
@@ -2267,16 +3205,67 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
     </div>
     
     <script>
-        async function saveGeneralSettings() {
-            const settings = {
-                calendarBackend: document.getElementById('calendarBackend').value,
-                refreshInterval: parseInt(document.getElementById('refreshInterval').value),
-                showDuration: document.getElementById('showDuration').checked,
+        // fieldElement finds the input of a setting named as in config.json
+        function fieldElement(field) {
+            const ids = {
+                calendar_backend: 'calendarBackend',
+                secret_store: 'secretStore',
+                refresh_interval: 'refreshInterval',
+                max_meetings: 'maxMeetings',
+                max_title_length: 'maxTitleLength',
+                current_meeting_format: 'currentMeetingFormat',
+                upcoming_meeting_format: 'upcomingMeetingFormat'
+            };
+            return ids[field] ? document.getElementById(ids[field]) : null;
+        }
+        
+        // showFieldErrors puts the message of each invalid setting below its input
+        function showFieldErrors(fieldErrors) {
+            document.querySelectorAll('.field-error').forEach(el => el.remove());
+            Object.entries(fieldErrors || {}).forEach(([field, message]) => {
+                const input = fieldElement(field);
+                if (!input) {
+                    return;
+                }
+                const note = document.createElement('small');
+                note.className = 'field-error';
+                note.textContent = message;
+                input.insertAdjacentElement('afterend', note);
+            });
+        }
+        
+        // markReadOnly disables the inputs of settings that cannot be changed
+        // here, such as those locked by an administrator, and says why
+        function markReadOnly(readOnly) {
+            Object.entries(readOnly || {}).forEach(([field, reason]) => {
+                const input = document.getElementById(field.replace(/_([a-z])/g, (match, letter) => letter.toUpperCase()));
+                if (!input) {
+                    return;
+                }
+                input.disabled = true;
+                
+                const note = document.createElement('small');
+                note.className = 'read-only-note';
+                note.textContent = '🔒 ' + reason;
+                const item = input.closest('.setting-item');
+                (item ? item.querySelector('.setting-info') : input.parentElement).appendChild(note);
+            });
+        }
+        
+        markReadOnly({{.ReadOnly}});
+        
+        async function saveGeneralSettings() {
+            const settings = {
+                calendarBackend: document.getElementById('calendarBackend').value,
+                secretStore: document.getElementById('secretStore').value,
+                refreshInterval: parseInt(document.getElementById('refreshInterval').value),
+                showDuration: document.getElementById('showDuration').checked,
                 maxMeetings: parseInt(document.getElementById('maxMeetings').value),
                 maxTitleLength: parseInt(document.getElementById('maxTitleLength').value),
                 currentMeetingFormat: document.getElementById('currentMeetingFormat').value,
                 upcomingMeetingFormat: document.getElementById('upcomingMeetingFormat').value,
-                startWithSystem: document.getElementById('startWithSystem').checked,
+                launchAtLogin: document.getElementById('launchAtLogin').checked,
+                autostartMethod: document.getElementById('autostartMethod').value,
                 autoRefreshStartup: document.getElementById('autoRefreshStartup').checked
             };
             
@@ -2288,6 +3277,7 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
                 });
                 
                 const result = await response.json();
+                showFieldErrors(result.data && result.data.fieldErrors);
                 
                 if (result.success) {
                     alert('✅ General settings saved successfully!');
@@ -2300,6 +3290,109 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
             }
         }
         
+        async function exportSettings() {
+            const format = document.getElementById('exportFormat').value;
+            try {
+                const response = await fetch('/api/general', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({
+                        action: 'export',
+                        bundle: { format: format, passphrase: document.getElementById('exportPassphrase').value }
+                    })
+                });
+                
+                const result = await response.json();
+                if (!result.success) {
+                    alert('❌ Error: ' + result.message);
+                    return;
+                }
+                
+                const link = document.createElement('a');
+                link.href = URL.createObjectURL(new Blob([result.data.content], { type: 'text/plain' }));
+                link.download = result.data.filename;
+                link.click();
+                URL.revokeObjectURL(link.href);
+            } catch (error) {
+                alert('❌ Error exporting settings: ' + error.message);
+            }
+        }
+        
+        // importRequest sends the chosen file to the import action, which is
+        // either 'preview-import' or 'import'
+        async function importRequest(action) {
+            const file = document.getElementById('importFile').files[0];
+            if (!file) {
+                throw new Error('Choose a settings file first');
+            }
+            const response = await fetch('/api/general', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    action: action,
+                    bundle: {
+                        content: await file.text(),
+                        mode: document.getElementById('importMode').value,
+                        passphrase: document.getElementById('importPassphrase').value
+                    }
+                })
+            });
+            return response.json();
+        }
+        
+        async function previewImport() {
+            const preview = document.getElementById('importPreview');
+            preview.style.display = 'none';
+            try {
+                const result = await importRequest('preview-import');
+                if (!result.success) {
+                    alert('❌ Error: ' + result.message);
+                    return;
+                }
+                
+                const changes = result.data.changes || [];
+                const body = document.getElementById('importChanges');
+                body.innerHTML = '';
+                changes.forEach(change => {
+                    const row = body.insertRow();
+                    row.style.borderBottom = '1px solid #f1f5f9';
+                    [change.key, change.old || '(not set)', change.new || '(not set)'].forEach((text, i) => {
+                        const cell = row.insertCell();
+                        cell.style.padding = '6px';
+                        cell.style.fontFamily = i === 0 ? 'inherit' : 'monospace';
+                        cell.style.wordBreak = 'break-all';
+                        cell.textContent = text;
+                    });
+                });
+                
+                let summary = changes.length === 0
+                    ? 'The file matches your current settings.'
+                    : changes.length + ' setting(s) will change:';
+                if (result.data.hasClientSecrets && !document.getElementById('importPassphrase').value) {
+                    summary += ' The file contains client secrets; enter its passphrase to import them.';
+                }
+                document.getElementById('importSummary').textContent = summary;
+                document.getElementById('applyImport').style.display = changes.length === 0 ? 'none' : '';
+                preview.style.display = 'block';
+            } catch (error) {
+                alert('❌ Error reading settings file: ' + error.message);
+            }
+        }
+        
+        async function applyImport() {
+            try {
+                const result = await importRequest('import');
+                if (result.success) {
+                    alert('✅ ' + result.message);
+                    location.reload();
+                } else {
+                    alert('❌ Error: ' + result.message);
+                }
+            } catch (error) {
+                alert('❌ Error importing settings: ' + error.message);
+            }
+        }
+        
         async function resetToDefaults() {
             if (!confirm('Are you sure you want to reset all settings to defaults? This will not affect your accounts or OAuth2 credentials.')) {
                 return;
@@ -2358,11 +3451,15 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
 </html>`
 
 	data := struct {
-		Config     *config.Config
-		ConfigJSON string
+		Config      *config.Config
+		ConfigJSON  string
+		SecretStore string
+		ReadOnly    map[string]string
 	}{
-		Config:     wsm.config,
-		ConfigJSON: wsm.getConfigJSON(),
+		Config:      cfg,
+		ConfigJSON:  wsm.getConfigJSON(),
+		SecretStore: config.ActiveSecretStore().Description(),
+		ReadOnly:    config.ReadOnlySettings(),
 	}
 
 	t, err := template.New("general").Parse(tmpl)
@@ -2376,6 +3473,150 @@ func (wsm *WebSettingsManager) handleGeneralPage(w http.ResponseWriter, r *http.
 }
 
 // Placeholder API handlers
+func (wsm *WebSettingsManager) handleHistoryPage(w http.ResponseWriter, r *http.Request) {
+	tmpl := `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Reminder History - MeetingBar</title>
+    <style>
+        * { margin: 0; padding: 0; box-sizing: border-box; }
+        
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            min-height: 100vh;
+            padding: 20px;
+        }
+        
+        .container {
+            max-width: 900px;
+            margin: 0 auto;
+            background: white;
+            border-radius: 12px;
+            box-shadow: 0 20px 40px rgba(0,0,0,0.1);
+            overflow: hidden;
+        }
+        
+        .header {
+            background: linear-gradient(135deg, #4facfe 0%, #00f2fe 100%);
+            color: white;
+            padding: 30px;
+            text-align: center;
+        }
+        
+        .content {
+            padding: 40px;
+        }
+        
+        .back-link {
+            display: inline-block;
+            margin-bottom: 20px;
+            color: #3b82f6;
+            text-decoration: none;
+        }
+        
+        .back-link:hover {
+            text-decoration: underline;
+        }
+        
+        table {
+            width: 100%;
+            border-collapse: collapse;
+        }
+        
+        th, td {
+            text-align: left;
+            padding: 12px;
+            border-bottom: 1px solid #e2e8f0;
+            font-size: 0.95rem;
+        }
+        
+        th {
+            color: #64748b;
+            font-weight: 600;
+        }
+        
+        .action {
+            display: inline-block;
+            padding: 2px 10px;
+            border-radius: 12px;
+            font-size: 0.85rem;
+            background: #f1f5f9;
+            color: #475569;
+        }
+        
+        .action.joined { background: #dcfce7; color: #166534; }
+        .action.expired { background: #fee2e2; color: #991b1b; }
+        
+        .empty {
+            text-align: center;
+            color: #64748b;
+            padding: 40px;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>🕘 Reminder History</h1>
+            <p>Reminders shown during the last 7 days</p>
+        </div>
+        
+        <div class="content">
+            <a href="/notifications" class="back-link">← Back to Notifications</a>
+            
+            {{if .Entries}}
+            <table>
+                <thead>
+                    <tr>
+                        <th>Sent</th>
+                        <th>Meeting</th>
+                        <th>Meeting Time</th>
+                        <th>Reminder</th>
+                        <th>Action</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Entries}}
+                    <tr>
+                        <td>{{.SentAt.Format "Mon 15:04"}}</td>
+                        <td>{{.MeetingTitle}}</td>
+                        <td>{{.StartTime.Format "15:04"}} - {{.EndTime.Format "15:04"}}</td>
+                        <td>{{typeLabel .Type}}</td>
+                        <td><span class="action {{.Action}}">{{actionLabel .Action}}</span></td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{else}}
+            <p class="empty">No reminders have been shown yet.</p>
+            {{end}}
+        </div>
+    </div>
+</body>
+</html>`
+
+	data := struct {
+		Entries []HistoryEntry
+	}{
+		Entries: wsm.notificationMgr.RecentReminders(0),
+	}
+
+	t, err := template.New("history").Funcs(template.FuncMap{
+		"typeLabel":   historyTypeLabel,
+		"actionLabel": historyActionLabel,
+	}).Parse(tmpl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	t.Execute(w, data)
+}
+
 func (wsm *WebSettingsManager) handleAccountsAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Not implemented yet"})
@@ -2402,10 +3643,11 @@ func (wsm *WebSettingsManager) handleCalendarsAPI(w http.ResponseWriter, r *http
 	switch data.Action {
 	case "save":
 		// Update enabled calendars
-		wsm.config.EnabledCalendars = data.SelectedCalendars
-		
-		// Save configuration
-		if err := wsm.config.Save(); err != nil {
+		err := wsm.store.Update(func(cfg *config.Config) error {
+			cfg.EnabledCalendars = data.SelectedCalendars
+			return nil
+		})
+		if err != nil {
 			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
 			return
 		}
@@ -2418,6 +3660,7 @@ func (wsm *WebSettingsManager) handleCalendarsAPI(w http.ResponseWriter, r *http
 }
 
 func (wsm *WebSettingsManager) handleNotificationsAPI(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != "POST" {
@@ -2426,13 +3669,28 @@ func (wsm *WebSettingsManager) handleNotificationsAPI(w http.ResponseWriter, r *
 	}
 
 	var data struct {
-		Action   string `json:"action"`
-		Settings struct {
+		Action       string `json:"action"`
+		ReminderType string `json:"reminderType"`
+		SoundFile    string `json:"soundFile"`
+		Settings     struct {
 			EnableNotifications      bool `json:"enableNotifications"`
 			NotificationTime         int  `json:"notificationTime"`
 			ShowMeetingLinks         bool `json:"showMeetingLinks"`
 			PersistentNotifications  bool `json:"persistentNotifications"`
+			InterruptiveReminders    bool `json:"interruptiveReminders"`
 			NotificationSound        bool `json:"notificationSound"`
+			NotifyRescheduled        bool `json:"notifyRescheduled"`
+			NotifyCancelled          bool `json:"notifyCancelled"`
+			NotifyLinkChanged        bool `json:"notifyLinkChanged"`
+			NotifyNewMeetings        bool `json:"notifyNewMeetings"`
+			NotifyMeetingEnding      bool `json:"notifyMeetingEnding"`
+			MeetingEndingTime        int  `json:"meetingEndingTime"`
+			NotifyOverlap            bool `json:"notifyOverlap"`
+			RespectDoNotDisturb      bool `json:"respectDoNotDisturb"`
+			QuietMode                string `json:"quietMode"`
+			QuietHours               []config.QuietHours `json:"quietHours"`
+			AlwaysAlert              []config.AlertRule `json:"alwaysAlert"`
+			SoundFiles               map[string]string `json:"soundFiles"`
 		} `json:"settings"`
 	}
 
@@ -2444,15 +3702,34 @@ func (wsm *WebSettingsManager) handleNotificationsAPI(w http.ResponseWriter, r *
 	switch data.Action {
 	case "save":
 		// Update notification settings
-		wsm.config.EnableNotifications = data.Settings.EnableNotifications
-		wsm.config.NotificationTime = data.Settings.NotificationTime
-		wsm.config.ShowMeetingLinks = data.Settings.ShowMeetingLinks
-		wsm.config.PersistentNotifications = data.Settings.PersistentNotifications
-		wsm.config.NotificationSound = data.Settings.NotificationSound
+		if !wsm.saveSettings(w, func(updated *config.Config) {
+			updated.EnableNotifications = data.Settings.EnableNotifications
+			updated.NotificationTime = data.Settings.NotificationTime
+			updated.ShowMeetingLinks = data.Settings.ShowMeetingLinks
+			updated.PersistentNotifications = data.Settings.PersistentNotifications
+			updated.InterruptiveReminders = data.Settings.InterruptiveReminders
+			updated.NotificationSound = data.Settings.NotificationSound
+			updated.NotifyRescheduled = data.Settings.NotifyRescheduled
+			updated.NotifyCancelled = data.Settings.NotifyCancelled
+			updated.NotifyLinkChanged = data.Settings.NotifyLinkChanged
+			updated.NotifyNewMeetings = data.Settings.NotifyNewMeetings
+			updated.NotifyMeetingEnding = data.Settings.NotifyMeetingEnding
+			updated.MeetingEndingTime = data.Settings.MeetingEndingTime
+			updated.NotifyOverlap = data.Settings.NotifyOverlap
+			updated.RespectDoNotDisturb = data.Settings.RespectDoNotDisturb
+			updated.QuietMode = data.Settings.QuietMode
+			updated.QuietHours = data.Settings.QuietHours
+			updated.AlwaysAlert = data.Settings.AlwaysAlert
 		
-		// Save configuration
-		if err := wsm.config.Save(); err != nil {
-			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
+			// Only keep the reminder types that have a custom sound
+			soundFiles := make(map[string]string)
+			for reminderType, file := range data.Settings.SoundFiles {
+				if file != "" {
+					soundFiles[reminderType] = file
+				}
+			}
+			updated.SoundFiles = soundFiles
+		}) {
 			return
 		}
 		
@@ -2476,6 +3753,18 @@ func (wsm *WebSettingsManager) handleNotificationsAPI(w http.ResponseWriter, r *
 		
 		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Test notification sent"})
 		
+	case "test-sound":
+		// Preview the sound as currently entered, before it is saved
+		testSound := reminderSound(cfg, ReminderType(data.ReminderType))
+		testSound.File = data.SoundFile
+		
+		if err := playTestSound(testSound); err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to play test sound: " + err.Error()})
+			return
+		}
+		
+		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Test sound played"})
+		
 	default:
 		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid action"})
 	}
@@ -2493,15 +3782,23 @@ func (wsm *WebSettingsManager) handleGeneralAPI(w http.ResponseWriter, r *http.R
 		Action   string `json:"action"`
 		Settings struct {
 			CalendarBackend       string `json:"calendarBackend"`
+			SecretStore           string `json:"secretStore"`
 			RefreshInterval       int    `json:"refreshInterval"`
 			ShowDuration          bool   `json:"showDuration"`
 			MaxMeetings           int    `json:"maxMeetings"`
 			MaxTitleLength        int    `json:"maxTitleLength"`
 			CurrentMeetingFormat  string `json:"currentMeetingFormat"`
 			UpcomingMeetingFormat string `json:"upcomingMeetingFormat"`
-			StartWithSystem       bool   `json:"startWithSystem"`
+			LaunchAtLogin         bool   `json:"launchAtLogin"`
+			AutostartMethod       string `json:"autostartMethod"`
 			AutoRefreshStartup    bool   `json:"autoRefreshStartup"`
 		} `json:"settings"`
+		Bundle struct {
+			Content    string `json:"content"`
+			Format     string `json:"format"`
+			Mode       string `json:"mode"`
+			Passphrase string `json:"passphrase"`
+		} `json:"bundle"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
@@ -2512,38 +3809,35 @@ func (wsm *WebSettingsManager) handleGeneralAPI(w http.ResponseWriter, r *http.R
 	switch data.Action {
 	case "save":
 		// Update general settings
-		wsm.config.CalendarBackend = data.Settings.CalendarBackend
-		wsm.config.RefreshInterval = data.Settings.RefreshInterval
-		wsm.config.ShowDuration = data.Settings.ShowDuration
-		wsm.config.MaxMeetings = data.Settings.MaxMeetings
-		wsm.config.MaxTitleLength = data.Settings.MaxTitleLength
-		wsm.config.CurrentMeetingFormat = data.Settings.CurrentMeetingFormat
-		wsm.config.UpcomingMeetingFormat = data.Settings.UpcomingMeetingFormat
-		wsm.config.StartWithSystem = data.Settings.StartWithSystem
-		wsm.config.AutoRefreshStartup = data.Settings.AutoRefreshStartup
-		
-		// Save configuration
-		if err := wsm.config.Save(); err != nil {
-			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
+		if !wsm.saveSettings(w, func(updated *config.Config) {
+			updated.CalendarBackend = data.Settings.CalendarBackend
+			updated.SecretStore = data.Settings.SecretStore
+			updated.RefreshInterval = data.Settings.RefreshInterval
+			updated.ShowDuration = data.Settings.ShowDuration
+			updated.MaxMeetings = data.Settings.MaxMeetings
+			updated.MaxTitleLength = data.Settings.MaxTitleLength
+			updated.CurrentMeetingFormat = data.Settings.CurrentMeetingFormat
+			updated.UpcomingMeetingFormat = data.Settings.UpcomingMeetingFormat
+			updated.LaunchAtLogin = data.Settings.LaunchAtLogin
+			updated.AutostartMethod = data.Settings.AutostartMethod
+			updated.AutoRefreshStartup = data.Settings.AutoRefreshStartup
+		}) {
 			return
 		}
 		
 		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "General settings saved successfully"})
 		
 	case "reset":
-		// Reset to defaults (preserve OAuth2 and accounts)
-		oauth2 := wsm.config.OAuth2
-		accounts := wsm.config.Accounts
-		
-		// Reset config to defaults
-		wsm.config = config.NewConfig()
-		
-		// Restore OAuth2 and accounts
-		wsm.config.OAuth2 = oauth2
-		wsm.config.Accounts = accounts
-		
-		// Save
-		if err := wsm.config.Save(); err != nil {
+		// Reset to defaults (preserve OAuth2 clients and accounts)
+		err := wsm.store.Update(func(cfg *config.Config) error {
+			defaults := config.NewConfig()
+			defaults.OAuth2 = cfg.OAuth2
+			defaults.OAuthClients = cfg.OAuthClients
+			defaults.Accounts = cfg.Accounts
+			*cfg = *defaults
+			return nil
+		})
+		if err != nil {
 			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
 			return
 		}
@@ -2552,22 +3846,128 @@ func (wsm *WebSettingsManager) handleGeneralAPI(w http.ResponseWriter, r *http.R
 		
 	case "clear":
 		// Clear all data
-		wsm.config = config.NewConfig()
-		
-		// Save empty config
-		if err := wsm.config.Save(); err != nil {
+		var removed []config.Account
+		err := wsm.store.Update(func(cfg *config.Config) error {
+			removed = cfg.Accounts
+			*cfg = *config.NewConfig()
+			return nil
+		})
+		if err != nil {
 			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
 			return
 		}
 		
+		// Revoke access and remove the tokens as removing each account does
+		var notRevoked []string
+		for _, account := range removed {
+			if err := calendar.RevokeAccount(wsm.ctx, account.ID); err != nil {
+				log.Printf("Failed to revoke account %s: %v", account.ID, err)
+				notRevoked = append(notRevoked, account.Email)
+			}
+		}
+		if len(notRevoked) > 0 {
+			json.NewEncoder(w).Encode(APIResponse{
+				Success: true,
+				Message: "All data cleared, but access could not be revoked at Google for " + strings.Join(notRevoked, ", ") + ". You can remove MeetingBar at https://myaccount.google.com/permissions",
+				Data:    map[string]bool{"revoked": false},
+			})
+			return
+		}
+		
 		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "All data cleared"})
 		
+	case "export":
+		content, err := wsm.store.Get().ExportBundle(data.Bundle.Format, data.Bundle.Passphrase)
+		if err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to export settings: " + err.Error()})
+			return
+		}
+		
+		json.NewEncoder(w).Encode(APIResponse{
+			Success: true,
+			Message: "Settings exported",
+			Data: map[string]interface{}{
+				"filename": "meetingbar-settings." + data.Bundle.Format,
+				"content":  string(content),
+			},
+		})
+		
+	case "preview-import":
+		bundle, err := config.ParseBundle([]byte(data.Bundle.Content))
+		if err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: err.Error()})
+			return
+		}
+		_, changes, err := wsm.store.Get().ImportBundle(bundle, data.Bundle.Mode, data.Bundle.Passphrase)
+		if err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: err.Error()})
+			return
+		}
+		
+		json.NewEncoder(w).Encode(APIResponse{
+			Success: true,
+			Message: fmt.Sprintf("%d settings would change", len(changes)),
+			Data: map[string]interface{}{
+				"changes":          changes,
+				"hasClientSecrets": bundle.HasClientSecrets(),
+			},
+		})
+		
+	case "import":
+		bundle, err := config.ParseBundle([]byte(data.Bundle.Content))
+		if err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: err.Error()})
+			return
+		}
+		var changes []config.SettingChange
+		err = wsm.store.Update(func(cfg *config.Config) error {
+			imported, importChanges, err := cfg.ImportBundle(bundle, data.Bundle.Mode, data.Bundle.Passphrase)
+			if err != nil {
+				return err
+			}
+			*cfg = *imported
+			changes = importChanges
+			return nil
+		})
+		if err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to import settings: " + err.Error()})
+			return
+		}
+		
+		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: fmt.Sprintf("Imported settings, %d changed", len(changes))})
+		
 	default:
 		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid action"})
 	}
 }
 
+// saveSettings applies update to the settings and saves them if they are still
+// valid. Otherwise it reports every invalid setting by its config.json key in
+// the response's fieldErrors and leaves the current settings unchanged.
+func (wsm *WebSettingsManager) saveSettings(w http.ResponseWriter, update func(*config.Config)) bool {
+	err := wsm.store.Update(func(cfg *config.Config) error {
+		update(cfg)
+		return cfg.Validate()
+	})
+	
+	var fieldErrors config.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		json.NewEncoder(w).Encode(APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    map[string]interface{}{"fieldErrors": fieldErrors.Fields()},
+		})
+		return false
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
+		return false
+	}
+	return true
+}
+
 func (wsm *WebSettingsManager) handleAddAccountAPI(w http.ResponseWriter, r *http.Request) {
+	cfg := wsm.store.Get()
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != "POST" {
@@ -2575,42 +3975,171 @@ func (wsm *WebSettingsManager) handleAddAccountAPI(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if OAuth2 credentials are configured
-	if wsm.config.OAuth2.ClientID == "" || wsm.config.OAuth2.ClientSecret == "" {
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "OAuth2 credentials not configured"})
+	// The mode is optional; the browser flow with the default client is the default.
+	// Missing client credentials are reported when the sign-in starts.
+	var data struct {
+		Mode      string `json:"mode"`
+		AccountID string `json:"accountId"`
+		Client    string `json:"client"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid JSON"})
+			return
+		}
+	}
+	
+	var session calendar.OAuthSession
+	var err error
+	if data.Mode == "reauth" {
+		session, err = wsm.startReauth(data.AccountID)
+	} else if data.Mode == calendar.OAuthModeDevice {
+		session, err = wsm.oauthSessions.StartDevice(wsm.ctx, cfg, data.Client, func(account *config.Account, err error) error {
+			clearDeviceCodeInTray()
+			if err != nil {
+				return nil
+			}
+			return wsm.addAuthorizedAccount(account)
+		})
+		if err == nil {
+			showDeviceCodeInTray(session.UserCode, session.VerificationURL, session.ExpiresAt)
+		}
+	} else {
+		session, err = wsm.oauthSessions.StartBrowser(wsm.ctx, cfg, data.Client, func(account *config.Account, err error) error {
+			if err != nil {
+				return nil
+			}
+			return wsm.addAuthorizedAccount(account)
+		})
+	}
+	if err != nil {
+		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: err.Error()})
 		return
 	}
 
-	// Start the full OAuth2 flow (this includes starting the callback server)
-	go func() {
-		account, err := calendar.StartOAuth2Flow(wsm.ctx, wsm.config)
-		if err != nil {
-			log.Printf("OAuth2 flow failed: %v", err)
+	json.NewEncoder(w).Encode(APIResponse{
+		Success: true,
+		Message: "Authentication flow started",
+		Data:    oauthSessionData(session),
+	})
+}
+
+// handleOAuthSessionAPI reports the progress of a sign-in on GET and cancels it on POST
+func (wsm *WebSettingsManager) handleOAuthSessionAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		session, ok := wsm.oauthSessions.Status(r.URL.Query().Get("id"))
+		if !ok {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Sign-in session not found"})
 			return
 		}
+		json.NewEncoder(w).Encode(APIResponse{Success: true, Data: oauthSessionData(session)})
 		
-		// Add account to config
-		wsm.config.Accounts = append(wsm.config.Accounts, *account)
-		if err := wsm.config.Save(); err != nil {
-			log.Printf("Failed to save config after adding account: %v", err)
+	case "POST":
+		var data struct {
+			Action string `json:"action"`
+			ID     string `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid JSON"})
+			return
+		}
+		if data.Action != "cancel" {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Invalid action"})
 			return
 		}
 		
-		log.Printf("Successfully added account: %s", account.Email)
-	}()
+		if err := wsm.oauthSessions.Cancel(data.ID); err != nil {
+			json.NewEncoder(w).Encode(APIResponse{Success: false, Message: err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Sign-in cancelled"})
+		
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
 
-	// Generate OAuth URL for immediate redirect
-	authURL, err := wsm.calendarService.GetAuthURL()
-	if err != nil {
-		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to generate auth URL: " + err.Error()})
-		return
+// oauthSessionData is the JSON form of a sign-in session used by the accounts page
+func oauthSessionData(session calendar.OAuthSession) map[string]string {
+	data := map[string]string{
+		"sessionId":       session.ID,
+		"mode":            session.Mode,
+		"status":          string(session.Status),
+		"statusText":      session.Describe(),
+		"error":           session.Error,
+		"authUrl":         session.AuthURL,
+		"userCode":        session.UserCode,
+		"verificationUrl": session.VerificationURL,
+		"expiresAt":       session.ExpiresAt.Local().Format("15:04"),
+	}
+	if session.Account != nil {
+		data["email"] = session.Account.Email
+		data["warning"] = calendar.MissingScopesWarning(session.Account)
 	}
+	return data
+}
 
-	json.NewEncoder(w).Encode(APIResponse{
-		Success: true,
-		Message: "Authentication flow started",
-		Data: map[string]string{"authUrl": authURL},
+// startReauth signs in again to an account that needs it, keeping its place
+// in the config and with it the calendar selection
+func (wsm *WebSettingsManager) startReauth(accountID string) (calendar.OAuthSession, error) {
+	account, ok := wsm.store.Account(accountID)
+	if !ok {
+		return calendar.OAuthSession{}, fmt.Errorf("account not found")
+	}
+	
+	return wsm.oauthSessions.StartReauth(wsm.ctx, wsm.store.Get(), account, func(signedIn *config.Account, err error) error {
+		if err != nil {
+			return nil
+		}
+		
+		err = wsm.store.Update(func(cfg *config.Config) error {
+			for i := range cfg.Accounts {
+				if cfg.Accounts[i].ID == signedIn.ID {
+					cfg.Accounts[i].NeedsReauth = false
+					cfg.Accounts[i].AuthError = ""
+					cfg.Accounts[i].MissingScopes = signedIn.MissingScopes
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		
+		if trayManager != nil {
+			go trayManager.refreshMeetings()
+		}
+		return nil
+	})
+}
+
+// addAuthorizedAccount saves an account once its sign-in has completed
+func (wsm *WebSettingsManager) addAuthorizedAccount(account *config.Account) error {
+	// Signing in to an account that is already configured refreshes its credentials
+	err := wsm.store.Update(func(cfg *config.Config) error {
+		found := false
+		for i := range cfg.Accounts {
+			if cfg.Accounts[i].ID == account.ID {
+				cfg.Accounts[i].NeedsReauth = false
+				cfg.Accounts[i].AuthError = ""
+				cfg.Accounts[i].OAuthClient = account.OAuthClient
+				found = true
+			}
+		}
+		if !found {
+			cfg.Accounts = append(cfg.Accounts, *account)
+		}
+		return nil
 	})
+	if err != nil {
+		return fmt.Errorf("failed to save config after adding account: %w", err)
+	}
+	
+	log.Printf("Successfully added account: %s", account.Email)
+	return nil
 }
 
 func (wsm *WebSettingsManager) handleRemoveAccountAPI(w http.ResponseWriter, r *http.Request) {
@@ -2636,54 +4165,64 @@ func (wsm *WebSettingsManager) handleRemoveAccountAPI(w http.ResponseWriter, r *
 	}
 
 	// Find and remove account
-	found := false
-	for i, account := range wsm.config.Accounts {
-		if account.ID == data.AccountID {
-			// Remove from slice
-			wsm.config.Accounts = append(wsm.config.Accounts[:i], wsm.config.Accounts[i+1:]...)
-			found = true
-			break
+	errAccountNotFound := errors.New("account not found")
+	err := wsm.store.Update(func(cfg *config.Config) error {
+		for i, account := range cfg.Accounts {
+			if account.ID == data.AccountID {
+				cfg.Accounts = append(cfg.Accounts[:i], cfg.Accounts[i+1:]...)
+				return nil
+			}
 		}
-	}
+		return errAccountNotFound
+	})
 
-	if !found {
+	if errors.Is(err, errAccountNotFound) {
 		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Account not found"})
 		return
 	}
-
-	// Save configuration
-	if err := wsm.config.Save(); err != nil {
+	if err != nil {
 		json.NewEncoder(w).Encode(APIResponse{Success: false, Message: "Failed to save configuration: " + err.Error()})
 		return
 	}
 
-	// Remove from keyring (optional, ignore errors)
-	wsm.calendarService.RemoveAccount(data.AccountID)
+	// Revoke access and remove the token; the account is gone from the config either way
+	if err := calendar.RevokeAccount(wsm.ctx, data.AccountID); err != nil {
+		log.Printf("Failed to revoke account %s: %v", data.AccountID, err)
+		json.NewEncoder(w).Encode(APIResponse{
+			Success: true,
+			Message: "Account removed, but its access could not be revoked at Google (" + err.Error() + "). You can remove MeetingBar at https://myaccount.google.com/permissions",
+			Data:    map[string]bool{"revoked": false},
+		})
+		return
+	}
 
 	json.NewEncoder(w).Encode(APIResponse{Success: true, Message: "Account removed successfully"})
 }
 
 // Helper methods
 func (wsm *WebSettingsManager) getNotificationStatus() string {
-	if wsm.config.EnableNotifications {
-		return fmt.Sprintf("✅ %dm before", wsm.config.NotificationTime)
+	cfg := wsm.store.Get()
+	if cfg.EnableNotifications {
+		return fmt.Sprintf("✅ %dm before", cfg.NotificationTime)
 	}
 	return "❌ Disabled"
 }
 
 func (wsm *WebSettingsManager) getClientIDPreview() string {
-	if wsm.config.OAuth2.ClientID == "" {
+	cfg := wsm.store.Get()
+	if cfg.OAuth2.ClientID == "" {
 		return ""
 	}
-	if len(wsm.config.OAuth2.ClientID) > 16 {
-		return wsm.config.OAuth2.ClientID[:8] + "..." + wsm.config.OAuth2.ClientID[len(wsm.config.OAuth2.ClientID)-8:]
+	if len(cfg.OAuth2.ClientID) > 16 {
+		return cfg.OAuth2.ClientID[:8] + "..." + cfg.OAuth2.ClientID[len(cfg.OAuth2.ClientID)-8:]
 	}
-	return wsm.config.OAuth2.ClientID
+	return cfg.OAuth2.ClientID
 }
 
 func (wsm *WebSettingsManager) getAccountsInfo() []AccountInfo {
+	cfg := wsm.store.Get()
 	var accounts []AccountInfo
-	for _, account := range wsm.config.Accounts {
+	for _, account := range cfg.Accounts {
 		// Get first letter for avatar
 		avatar := "?"
 		if len(account.Email) > 0 {
@@ -2691,16 +4230,20 @@ func (wsm *WebSettingsManager) getAccountsInfo() []AccountInfo {
 		}
 		
 		accounts = append(accounts, AccountInfo{
-			ID:      account.ID,
-			Email:   account.Email,
-			Avatar:  avatar,
-			AddedAt: account.AddedAt.Format("Jan 2, 2006"),
+			ID:          account.ID,
+			Email:       account.Email,
+			Avatar:      avatar,
+			AddedAt:     account.AddedAt.Format("Jan 2, 2006"),
+			NeedsReauth: account.NeedsReauth,
+			AuthError:   account.AuthError,
+			OAuthClient: account.OAuthClient,
 		})
 	}
 	return accounts
 }
 
 func (wsm *WebSettingsManager) getAccountCalendarsInfo() []AccountCalendarsInfo {
+	cfg := wsm.store.Get()
 	var accountCalendars []AccountCalendarsInfo
 	
 	if wsm.calendarService.IsGnomeBackend() {
@@ -2716,7 +4259,7 @@ func (wsm *WebSettingsManager) getAccountCalendarsInfo() []AccountCalendarsInfo
 		for _, cal := range calendars {
 			// Check if calendar is selected
 			selected := false
-			for _, enabledID := range wsm.config.EnabledCalendars {
+			for _, enabledID := range cfg.EnabledCalendars {
 				if enabledID == cal.ID {
 					selected = true
 					break
@@ -2749,7 +4292,7 @@ func (wsm *WebSettingsManager) getAccountCalendarsInfo() []AccountCalendarsInfo
 	}
 	
 	// For Google backend, iterate through accounts
-	for _, account := range wsm.config.Accounts {
+	for _, account := range cfg.Accounts {
 		// Get first letter for avatar
 		avatar := "?"
 		if len(account.Email) > 0 {
@@ -2767,7 +4310,7 @@ func (wsm *WebSettingsManager) getAccountCalendarsInfo() []AccountCalendarsInfo
 		for _, cal := range calendars {
 			// Check if calendar is selected
 			selected := false
-			for _, enabledID := range wsm.config.EnabledCalendars {
+			for _, enabledID := range cfg.EnabledCalendars {
 				if enabledID == cal.ID {
 					selected = true
 					break
@@ -2781,9 +4324,9 @@ func (wsm *WebSettingsManager) getAccountCalendarsInfo() []AccountCalendarsInfo
 			}
 			
 			description := "Calendar"
-			if wsm.config.CalendarBackend == "google" {
+			if cfg.CalendarBackend == "google" {
 				description = "Google Calendar"
-			} else if wsm.config.CalendarBackend == "gnome" {
+			} else if cfg.CalendarBackend == "gnome" {
 				description = "GNOME Calendar"
 			}
 			
@@ -2808,18 +4351,19 @@ func (wsm *WebSettingsManager) getAccountCalendarsInfo() []AccountCalendarsInfo
 }
 
 func (wsm *WebSettingsManager) getNotificationPreview() string {
-	if !wsm.config.EnableNotifications {
+	cfg := wsm.store.Get()
+	if !cfg.EnableNotifications {
 		return "Notifications: Disabled"
 	}
 	
-	preview := fmt.Sprintf("Notifications: Enabled, %d minutes before meetings", wsm.config.NotificationTime)
-	if wsm.config.ShowMeetingLinks {
+	preview := fmt.Sprintf("Notifications: Enabled, %d minutes before meetings", cfg.NotificationTime)
+	if cfg.ShowMeetingLinks {
 		preview += ", with meeting links"
 	}
-	if wsm.config.PersistentNotifications {
+	if cfg.PersistentNotifications {
 		preview += ", persistent"
 	}
-	if wsm.config.NotificationSound {
+	if cfg.NotificationSound {
 		preview += ", with sound"
 	}
 	
@@ -2827,41 +4371,10 @@ func (wsm *WebSettingsManager) getNotificationPreview() string {
 }
 
 func (wsm *WebSettingsManager) getConfigJSON() string {
-	configBytes, err := json.MarshalIndent(wsm.config, "", "  ")
+	cfg := wsm.store.Get()
+	configBytes, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return "Error marshaling config: " + err.Error()
 	}
 	return string(configBytes)
 }
-
-func (wsm *WebSettingsManager) handleOAuthSuccess(w http.ResponseWriter, r *http.Request) {
-	tmpl := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Account Added - MeetingBar</title>
-    <style>
-        body { font-family: system-ui; text-align: center; padding: 50px; background: #f0f9ff; }
-        .success { background: #10b981; color: white; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
-        .btn { background: #3b82f6; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block; }
-    </style>
-</head>
-<body>
-    <div class="success">
-        <h2>✅ Account Added Successfully!</h2>
-        <p>Your Google account has been added to MeetingBar.</p>
-    </div>
-    <a href="/accounts" class="btn">Return to Accounts</a>
-    <script>
-        // Auto-close after 5 seconds
-        setTimeout(() => {
-            window.location.href = '/accounts';
-        }, 5000);
-    </script>
-</body>
-</html>`
-
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(tmpl))
-}
\ No newline at end of file